@@ -37,62 +37,75 @@ func Abandon(middleware web.MiddlewareType) error {
 
 // Handle adds a route to the default Mux. See the documentation for web.Mux for
 // more information about what types this function accepts.
-func Handle(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Handle(pattern, handler)
+func Handle(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Handle(pattern, handler, middleware...)
 }
 
 // Connect adds a CONNECT route to the default Mux. See the documentation for
 // web.Mux for more information about what types this function accepts.
-func Connect(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Connect(pattern, handler)
+func Connect(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Connect(pattern, handler, middleware...)
 }
 
 // Delete adds a DELETE route to the default Mux. See the documentation for
 // web.Mux for more information about what types this function accepts.
-func Delete(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Delete(pattern, handler)
+func Delete(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Delete(pattern, handler, middleware...)
 }
 
 // Get adds a GET route to the default Mux. See the documentation for web.Mux for
 // more information about what types this function accepts.
-func Get(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Get(pattern, handler)
+func Get(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Get(pattern, handler, middleware...)
 }
 
 // Head adds a HEAD route to the default Mux. See the documentation for web.Mux
 // for more information about what types this function accepts.
-func Head(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Head(pattern, handler)
+func Head(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Head(pattern, handler, middleware...)
 }
 
 // Options adds a OPTIONS route to the default Mux. See the documentation for
 // web.Mux for more information about what types this function accepts.
-func Options(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Options(pattern, handler)
+func Options(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Options(pattern, handler, middleware...)
 }
 
 // Patch adds a PATCH route to the default Mux. See the documentation for web.Mux
 // for more information about what types this function accepts.
-func Patch(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Patch(pattern, handler)
+func Patch(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Patch(pattern, handler, middleware...)
 }
 
 // Post adds a POST route to the default Mux. See the documentation for web.Mux
 // for more information about what types this function accepts.
-func Post(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Post(pattern, handler)
+func Post(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Post(pattern, handler, middleware...)
 }
 
 // Put adds a PUT route to the default Mux. See the documentation for web.Mux for
 // more information about what types this function accepts.
-func Put(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Put(pattern, handler)
+func Put(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Put(pattern, handler, middleware...)
 }
 
 // Trace adds a TRACE route to the default Mux. See the documentation for
 // web.Mux for more information about what types this function accepts.
-func Trace(pattern web.PatternType, handler web.HandlerType) {
-	DefaultMux.Trace(pattern, handler)
+func Trace(pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Trace(pattern, handler, middleware...)
+}
+
+// Method adds a route to the default Mux for the given HTTP method, which is
+// most often an extension method such as PROPFIND. See the documentation for
+// web.Mux.Method for more information.
+func Method(method string, pattern web.PatternType, handler web.HandlerType, middleware ...web.MiddlewareType) *web.Route {
+	return DefaultMux.Method(method, pattern, handler, middleware...)
+}
+
+// URL generates a path for a named route on the default Mux. See the
+// documentation for web.Mux.URL for more information.
+func URL(name string, params map[string]string) (string, error) {
+	return DefaultMux.URL(name, params)
 }
 
 // NotFound sets the NotFound handler for the default Mux. See the documentation
@@ -100,3 +113,9 @@ func Trace(pattern web.PatternType, handler web.HandlerType) {
 func NotFound(handler web.HandlerType) {
 	DefaultMux.NotFound(handler)
 }
+
+// MethodNotAllowed sets the MethodNotAllowed handler for the default Mux. See
+// the documentation for web.Mux.MethodNotAllowed for more information.
+func MethodNotAllowed(handler web.HandlerType) {
+	DefaultMux.MethodNotAllowed(handler)
+}
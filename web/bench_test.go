@@ -90,7 +90,16 @@ func permuteRequests(reqs []*http.Request) []*http.Request {
 }
 
 func benchN(b *testing.B, n int) {
+	benchRouter(b, n, false)
+}
+
+func benchRadixN(b *testing.B, n int) {
+	benchRouter(b, n, true)
+}
+
+func benchRouter(b *testing.B, n int, radix bool) {
 	m := New()
+	m.RadixRouting(radix)
 	prefixes := genPrefixes(n)
 	for _, prefix := range prefixes {
 		addRoutes(m, prefix)
@@ -109,6 +118,31 @@ func benchN(b *testing.B, n int) {
 	})
 }
 
+// benchShared benchmarks a large number of routes whose literal prefixes are
+// identical because they begin with a named parameter. This is the worst case
+// for the bytecode router, which must try each of them in turn.
+func benchShared(b *testing.B, n int, radix bool) {
+	m := New()
+	m.RadixRouting(radix)
+	prefixes := genPrefixes(n)
+	for i, prefix := range prefixes {
+		addRoutes(m, "/api/:version"+prefix)
+		prefixes[i] = "/api/v1" + prefix
+	}
+	m.Compile()
+	reqs := permuteRequests(genRequests(prefixes))
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			i++
+			m.ServeHTTP(w, reqs[i%len(reqs)])
+		}
+	})
+}
+
 func benchM(b *testing.B, n int) {
 	m := New()
 	m.Get("/", nilRouter{})
@@ -155,6 +189,26 @@ func BenchmarkRoute5000(b *testing.B) {
 	benchN(b, 1000)
 }
 
+func BenchmarkRadixRoute5(b *testing.B) {
+	benchRadixN(b, 1)
+}
+func BenchmarkRadixRoute50(b *testing.B) {
+	benchRadixN(b, 10)
+}
+func BenchmarkRadixRoute500(b *testing.B) {
+	benchRadixN(b, 100)
+}
+func BenchmarkRadixRoute5000(b *testing.B) {
+	benchRadixN(b, 1000)
+}
+
+func BenchmarkSharedPrefix1500(b *testing.B) {
+	benchShared(b, 300, false)
+}
+func BenchmarkRadixSharedPrefix1500(b *testing.B) {
+	benchShared(b, 300, true)
+}
+
 func BenchmarkMiddleware1(b *testing.B) {
 	benchM(b, 1)
 }
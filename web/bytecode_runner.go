@@ -3,30 +3,56 @@ package web
 import "net/http"
 
 type routeMachine struct {
-	sm     stateMachine
-	routes []route
+	sm      stateMachine
+	tree    *radixTree
+	routes  []route
+	methods map[string]method
+	// If set, paths are lowercased before being compared to the (likewise
+	// lowercased) route prefixes.
+	fold bool
 }
 
-func matchRoute(route route, m method, ms *method, r *http.Request, c *C) bool {
+// matchRoute returns true if the given route matches the request. As a side
+// effect, it accumulates the set of methods the request's path would have been
+// valid for, as well as the status of the first route Predicate to fail. Since
+// 404 is the least informative status we can give, we prefer any other.
+func matchRoute(route route, m method, ms *method, status *int, r *http.Request, c *C) bool {
 	if !route.pattern.Match(r, c) {
 		return false
 	}
 	*ms |= route.method
 
-	if route.method&m != 0 {
-		route.pattern.Run(r, c)
-		return true
+	if route.method&m == 0 {
+		return false
+	}
+	for _, p := range route.predicates {
+		if !p.Check(r, c) {
+			if *status == 0 || *status == http.StatusNotFound {
+				*status = p.Status()
+			}
+			return false
+		}
 	}
-	return false
+	route.pattern.Run(r, c)
+	return true
 }
 
-func (rm routeMachine) route(c *C, w http.ResponseWriter, r *http.Request) (method, *route) {
-	m := httpMethod(r.Method)
+func (rm routeMachine) route(c *C, w http.ResponseWriter, r *http.Request) (method, int, *route) {
+	m := httpMethod(rm.methods, r.Method)
+	if rm.tree != nil {
+		return rm.tree.route(rm.routes, m, c, w, r)
+	}
+
 	var methods method
-	p := r.URL.Path
+	var status int
+	path := r.URL.Path
+	if rm.fold {
+		path = lowerASCII(path)
+	}
+	p := path
 
 	if len(rm.sm) == 0 {
-		return methods, nil
+		return methods, status, nil
 	}
 
 	var i int
@@ -34,7 +60,7 @@ func (rm routeMachine) route(c *C, w http.ResponseWriter, r *http.Request) (meth
 		sm := rm.sm[i].mode
 		if sm&smSetCursor != 0 {
 			si := rm.sm[i].i
-			p = r.URL.Path[si:]
+			p = path[si:]
 			i++
 			continue
 		}
@@ -67,13 +93,13 @@ func (rm routeMachine) route(c *C, w http.ResponseWriter, r *http.Request) (meth
 
 		if match && sm&smRoute != 0 {
 			si := rm.sm[i].i
-			if matchRoute(rm.routes[si], m, &methods, r, c) {
-				return 0, &rm.routes[si]
+			if matchRoute(rm.routes[si], m, &methods, &status, r, c) {
+				return 0, 0, &rm.routes[si]
 			}
 			i++
 		} else if match != (sm&smJumpOnMatch == 0) {
 			if sm&smFail != 0 {
-				return methods, nil
+				return methods, status, nil
 			}
 			i = int(rm.sm[i].i)
 		} else {
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// PathPolicy describes how a Mux treats requests whose path does not match any
+// route, but which would have matched had it been written canonically. See
+// Mux.PathPolicy.
+type PathPolicy int
+
+const (
+	// PathStrict routes paths exactly as given. This is the default.
+	PathStrict PathPolicy = iota
+	// PathRedirect redirects requests to the canonical path of the route
+	// they would have matched, preserving the query string. GET and HEAD
+	// requests are redirected with a 301 (Moved Permanently), and all other
+	// requests with a 308 (Permanent Redirect) so that clients will not
+	// change their method or drop the request body.
+	PathRedirect
+	// PathLenient serves requests using the route they would have matched,
+	// as if the path had been written canonically. The request itself is not
+	// modified.
+	PathLenient
+)
+
+// The key used by middleware which strips a prefix from the request's path
+// before passing it to a nested Mux (such as middleware.SubRouter) to record the
+// escaped form of the stripped prefix, so that redirects to canonical paths
+// (see PathRedirect) point at the full path. The value is a string.
+const MountPrefixKey = "goji.web.MountPrefix"
+
+// Not defined by net/http until Go 1.7.
+const statusPermanentRedirect = 308
+
+// cleanPath returns the canonical form of the given path: repeated slashes are
+// collapsed, and "." and ".." elements are resolved. A trailing slash is
+// preserved.
+func cleanPath(p string) string {
+	if p == "" || p[0] != '/' {
+		return p
+	}
+	np := path.Clean(p)
+	if p[len(p)-1] == '/' && np != "/" {
+		np += "/"
+	}
+	return np
+}
+
+// canonicalPaths returns the alternate paths we try when a request's path
+// matches no routes: its cleaned form, and the cleaned form with its trailing
+// slash added or removed.
+func canonicalPaths(p string) []string {
+	if p == "" || p[0] != '/' {
+		return nil
+	}
+	clean := cleanPath(p)
+	var toggled string
+	if strings.HasSuffix(clean, "/") {
+		toggled = strings.TrimSuffix(clean, "/")
+	} else {
+		toggled = clean + "/"
+	}
+
+	paths := make([]string, 0, 2)
+	if clean != p {
+		paths = append(paths, clean)
+	}
+	if toggled != "" && toggled != p {
+		paths = append(paths, toggled)
+	}
+	return paths
+}
+
+// canonicalMatch attempts to route the request using each of its canonical
+// paths in turn, returning a Match which handles the request according to the
+// router's path policy if one of them succeeds. Otherwise, it returns the set
+// of methods (and the predicate status, if any) of the first canonical path
+// which matched routes for other methods, so the request can be answered with a
+// 405 (Method Not Allowed) instead of a 404.
+func (rt *router) canonicalMatch(rm *routeMachine, c *C, w http.ResponseWriter, r *http.Request) (Match, method, int, bool) {
+	var methods method
+	var status int
+	for _, p := range canonicalPaths(r.URL.Path) {
+		r2 := *r
+		u := *r.URL
+		u.Path = p
+		u.RawPath = ""
+		r2.URL = &u
+
+		m, st, route := rm.route(c, w, &r2)
+		if route == nil {
+			if methods == 0 {
+				methods, status = m, st
+			}
+			continue
+		}
+		if rt.pathPolicy == PathLenient {
+			return route.match(), 0, 0, true
+		}
+
+		code := http.StatusMovedPermanently
+		if r.Method != "GET" && r.Method != "HEAD" {
+			code = statusPermanentRedirect
+		}
+		// If we're matching against escaped paths, p already is one.
+		target := p
+		if !rt.escapedPaths {
+			target = escapePath(p)
+		}
+		if prefix, ok := envMountPrefix(*c); ok {
+			target = prefix + target
+		}
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		return Match{Handler: redirectHandler{target, code}}, 0, 0, true
+	}
+	return Match{}, methods, status, false
+}
+
+type redirectHandler struct {
+	url  string
+	code int
+}
+
+func (h redirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, h.url, h.code)
+}
+
+func (h redirectHandler) ServeHTTPC(c C, w http.ResponseWriter, r *http.Request) {
+	h.ServeHTTP(w, r)
+}
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalPaths(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"/users", []string{"/users/"}},
+		{"/users/", []string{"/users"}},
+		{"/", []string{}},
+		{"//users", []string{"/users", "/users/"}},
+		{"/a/./b/../c/", []string{"/a/c/", "/a/c"}},
+		{"/a//b", []string{"/a/b", "/a/b/"}},
+		{"*", nil},
+	}
+	for _, test := range tests {
+		actual := canonicalPaths(test.path)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("canonicalPaths(%q): expected %q, got %q",
+				test.path, test.expected, actual)
+		}
+	}
+}
+
+// canonicalRequest builds a request with the given (possibly non-canonical)
+// path, which http.NewRequest would otherwise interpret as a URL.
+func canonicalRequest(method, path, query string) *http.Request {
+	r, _ := http.NewRequest(method, "/", nil)
+	r.URL.Path = path
+	r.URL.RawQuery = query
+	return r
+}
+
+func canonicalMux(policy PathPolicy, ch chan string) *Mux {
+	m := New()
+	m.PathPolicy(policy)
+	m.Get("/users", chHandler(ch, "users"))
+	m.Get("/posts/", chHandler(ch, "posts"))
+	m.Post("/users/:name", chHandler(ch, "user"))
+	m.NotFound(chHandler(ch, "404"))
+	return m
+}
+
+func TestPathStrict(t *testing.T) {
+	t.Parallel()
+	ch := make(chan string, 1)
+	m := canonicalMux(PathStrict, ch)
+
+	for _, path := range []string{"/users/", "//users", "/posts"} {
+		r := canonicalRequest("GET", path, "")
+		m.ServeHTTP(httptest.NewRecorder(), r)
+		if actual := <-ch; actual != "404" {
+			t.Errorf("%s: expected 404, got %q", path, actual)
+		}
+	}
+}
+
+func TestPathRedirect(t *testing.T) {
+	t.Parallel()
+	ch := make(chan string, 1)
+	m := canonicalMux(PathRedirect, ch)
+
+	tests := []struct {
+		method, path, query string
+		code                int
+		location            string
+	}{
+		{"GET", "/users/", "", http.StatusMovedPermanently, "/users"},
+		{"GET", "/users/", "page=2", http.StatusMovedPermanently, "/users?page=2"},
+		{"HEAD", "//users", "", http.StatusMovedPermanently, "/users"},
+		{"GET", "/posts", "", http.StatusMovedPermanently, "/posts/"},
+		{"GET", "/a/../posts/.", "", http.StatusMovedPermanently, "/posts/"},
+		{"POST", "/users/carl/", "", statusPermanentRedirect, "/users/carl"},
+	}
+	for _, test := range tests {
+		r := canonicalRequest(test.method, test.path, test.query)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%s %s: expected %d, got %d", test.method,
+				test.path, test.code, w.Code)
+		}
+		if loc := w.HeaderMap.Get("Location"); loc != test.location {
+			t.Errorf("%s %s: expected Location %q, got %q",
+				test.method, test.path, test.location, loc)
+		}
+	}
+
+	// Requests which would have matched are never redirected.
+	r, _ := http.NewRequest("GET", "/users", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	if actual := <-ch; actual != "users" {
+		t.Errorf("Expected users, got %q", actual)
+	}
+
+	// Nor are requests which would not have matched anyway.
+	r, _ = http.NewRequest("GET", "/nope/", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	if actual := <-ch; actual != "404" {
+		t.Errorf("Expected 404, got %q", actual)
+	}
+}
+
+func TestPathRedirectMethodNotAllowed(t *testing.T) {
+	t.Parallel()
+	ch := make(chan string, 1)
+	m := canonicalMux(PathRedirect, ch)
+
+	for _, path := range []string{"/users/", "//users"} {
+		r := canonicalRequest("POST", path, "")
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405, got %d", path, w.Code)
+		}
+		if allow := w.HeaderMap.Get("Allow"); allow != "GET, HEAD" {
+			t.Errorf("%s: expected Allow %q, got %q", path,
+				"GET, HEAD", allow)
+		}
+	}
+}
+
+func TestPathLenient(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.PathPolicy(PathLenient)
+	ch := make(chan string, 1)
+	m.Post("/users/:name", func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- c.URLParams["name"]
+	})
+
+	for _, path := range []string{"/users/carl/", "//users/./carl"} {
+		r := canonicalRequest("POST", path, "")
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", path, w.Code)
+		}
+		if actual := <-ch; actual != "carl" {
+			t.Errorf("%s: expected carl, got %q", path, actual)
+		}
+	}
+}
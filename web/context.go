@@ -0,0 +1,10 @@
+// +build !go1.7
+
+package web
+
+import "net/http"
+
+// Before Go 1.7, requests do not carry a context.Context to attach C to.
+func withC(r *http.Request, c C) *http.Request {
+	return r
+}
@@ -0,0 +1,36 @@
+// +build go1.7
+
+package web
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of the given context.Context which carries the
+// given Goji context. The URL parameters and environment of the Goji context
+// can later be retrieved using FromContext.
+//
+// If enabled using Mux.AttachContext, Goji attaches its context to the
+// request's context.Context before calling the routed handler, so net/http
+// handlers (such as http.HandlerFunc), and the libraries they call, can call
+// FromContext(r.Context()) to access it. Since the request's context.Context is
+// derived from the one the request arrived with, cancellation (for instance,
+// because the client went away) is visible in the usual way.
+func NewContext(ctx context.Context, c C) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the Goji context carried by the given context.Context,
+// if any (see NewContext).
+func FromContext(ctx context.Context) (C, bool) {
+	c, ok := ctx.Value(contextKey{}).(C)
+	return c, ok
+}
+
+// withC returns a request whose context.Context carries the given Goji context.
+func withC(r *http.Request, c C) *http.Request {
+	return r.WithContext(NewContext(r.Context(), c))
+}
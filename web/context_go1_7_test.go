@@ -0,0 +1,89 @@
+// +build go1.7
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextBridge(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.AttachContext(true)
+	m.Use(func(c *C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			c.Env["user"] = "carl"
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	})
+	ch := make(chan C, 1)
+	m.Get("/hello/:name", func(w http.ResponseWriter, r *http.Request) {
+		c, ok := FromContext(r.Context())
+		if !ok {
+			t.Error("Expected a C in the request context")
+		}
+		ch <- c
+	})
+
+	r, _ := http.NewRequest("GET", "/hello/world", nil)
+	m.ServeHTTPC(C{Env: map[interface{}]interface{}{}},
+		httptest.NewRecorder(), r)
+	c := <-ch
+	if c.URLParams["name"] != "world" {
+		t.Errorf("Expected name=world, got %v", c.URLParams)
+	}
+	if c.Env["user"] != "carl" {
+		t.Errorf("Expected user=carl, got %v", c.Env)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan error, 1)
+	m.Get("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		ch <- r.Context().Err()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, _ := http.NewRequest("GET", "/", nil)
+	r = r.WithContext(ctx)
+	cancel()
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	if err := <-ch; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFromContextMissing(t *testing.T) {
+	t.Parallel()
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("Expected no C in an empty context")
+	}
+	c := C{URLParams: map[string]string{"a": "b"}}
+	if c2, ok := FromContext(NewContext(context.Background(), c)); !ok ||
+		c2.URLParams["a"] != "b" {
+		t.Errorf("Expected %v, got %v", c, c2)
+	}
+}
+
+func TestContextBridgeDisabled(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan bool, 1)
+	m.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		_, ok := FromContext(r.Context())
+		ch <- ok
+	})
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	if <-ch {
+		t.Error("Expected no C in the request context by default")
+	}
+}
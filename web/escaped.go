@@ -0,0 +1,74 @@
+package web
+
+import "net/http"
+
+// escapedRequest returns a shallow copy of the given request whose path is the
+// request's escaped path, and a copy of the given context into which patterns
+// can bind their (still escaped) URL parameters.
+func escapedRequest(c *C, r *http.Request) (*C, *http.Request) {
+	r2 := *r
+	u := *r.URL
+	u.Path = r.URL.EscapedPath()
+	u.RawPath = ""
+	r2.URL = &u
+
+	c2 := *c
+	c2.URLParams = nil
+	return &c2, &r2
+}
+
+// mergeEscaped unescapes each of the URL parameters bound into the temporary
+// context returned by escapedRequest, and merges them into the given context.
+func mergeEscaped(c, tmp *C) {
+	c.Env = tmp.Env
+	if len(tmp.URLParams) == 0 {
+		return
+	}
+	if c.URLParams == nil {
+		c.URLParams = make(map[string]string, len(tmp.URLParams))
+	}
+	for k, v := range tmp.URLParams {
+		c.URLParams[k] = unescape(v)
+	}
+}
+
+// unescape decodes percent-encoded octets in the given path segment. Unlike
+// url.QueryUnescape, '+' is left alone. Malformed escapes are passed through
+// unchanged, although net/url will never give us any.
+func unescape(s string) string {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' {
+			n++
+		}
+	}
+	if n == 0 {
+		return s
+	}
+
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && ishex(s[i+1]) && ishex(s[i+2]) {
+			buf = append(buf, unhex(s[i+1])<<4|unhex(s[i+2]))
+			i += 2
+			continue
+		}
+		buf = append(buf, s[i])
+	}
+	return string(buf)
+}
+
+func ishex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestUnescape(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in, out string
+	}{
+		{"plain", "plain"},
+		{"a%2Fb", "a/b"},
+		{"a%2fb", "a/b"},
+		{"a+b%20c", "a+b c"},
+		{"%", "%"},
+		{"%zz", "%zz"},
+		{"100%", "100%"},
+	}
+	for _, test := range tests {
+		if actual := unescape(test.in); actual != test.out {
+			t.Errorf("unescape(%q): expected %q, got %q", test.in,
+				test.out, actual)
+		}
+	}
+}
+
+func TestEscapedPaths(t *testing.T) {
+	t.Parallel()
+	ch := make(chan map[string]string, 1)
+	h := func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- c.URLParams
+	}
+	m := New()
+	m.EscapedPaths(true)
+	m.Get("/objects/:key", h)
+	m.Get("/objects/:key/meta", h)
+	m.Get(regexp.MustCompile(`^/re/(?P<key>[^/]+)$`), h)
+	m.Get("/files/*", h)
+
+	tests := []struct {
+		path     string
+		key, val string
+	}{
+		{"/objects/a%2Fb", "key", "a/b"},
+		{"/objects/a%2Fb%20c/meta", "key", "a/b c"},
+		{"/objects/plain", "key", "plain"},
+		{"/re/x%2Fy", "key", "x/y"},
+		{"/files/a%2Fb/c", "*", "/a/b/c"},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest("GET", test.path, nil)
+		m.ServeHTTP(httptest.NewRecorder(), r)
+		params := <-ch
+		if params[test.key] != test.val {
+			t.Errorf("%s: expected %s=%q, got %v", test.path,
+				test.key, test.val, params)
+		}
+	}
+
+	// By default, the decoded path is used, so the slash splits the key.
+	m = New()
+	m.Get("/objects/:key", h)
+	r, _ := http.NewRequest("GET", "/objects/a%2Fb", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+}
+
+func TestEscapedPathsRedirect(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.EscapedPaths(true)
+	m.PathPolicy(PathRedirect)
+	m.Get("/objects/:key", http.NotFound)
+
+	r, _ := http.NewRequest("GET", "/objects/a%2Fb/", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if loc := w.HeaderMap.Get("Location"); loc != "/objects/a%2Fb" {
+		t.Errorf("Expected redirect to /objects/a%%2Fb, got %q", loc)
+	}
+}
@@ -0,0 +1,123 @@
+package web
+
+import "log"
+
+// CaseInsensitive returns a Pattern which matches the given string pattern (see
+// PatternType), except that its literal portions are compared
+// case-insensitively. URL parameters are bound using the case they were given
+// in the request. Only ASCII letters are folded.
+//
+// To match every string pattern on a Mux case-insensitively, see
+// Mux.CaseInsensitive.
+func CaseInsensitive(pattern string) Pattern {
+	return foldPattern(parseString(pattern))
+}
+
+// foldPrefixer is implemented by Patterns which support case-insensitive
+// prefix matching. foldPrefix returns the lowercased prefix of every path the
+// pattern matches, for use when the router itself compares prefixes
+// case-insensitively.
+type foldPrefixer interface {
+	foldPrefix() string
+}
+
+// foldPattern returns a case-insensitive version of the given Pattern, if it is
+// of a type which supports it.
+func foldPattern(p Pattern) Pattern {
+	switch v := p.(type) {
+	case stringPattern:
+		v.fold = true
+		return v
+	case optionalPattern:
+		alts := make([]stringPattern, len(v.alts))
+		for i, alt := range v.alts {
+			alt.fold = true
+			alts[i] = alt
+		}
+		v.alts = alts
+		v.prefix = alts[0].Prefix()
+		for _, alt := range alts[1:] {
+			v.prefix = commonPrefix(v.prefix, alt.Prefix())
+		}
+		return v
+	}
+	return p
+}
+
+// routePrefix returns the prefix the router should use for the given Pattern.
+// Case-insensitive routers lowercase every path before comparing it against
+// route prefixes.
+func (rt *router) routePrefix(p Pattern) string {
+	if !rt.fold {
+		return p.Prefix()
+	}
+	if fp, ok := p.(foldPrefixer); ok {
+		return fp.foldPrefix()
+	}
+	return lowerASCII(p.Prefix())
+}
+
+func (rt *router) setFold() {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	if len(rt.routes) != 0 {
+		log.Fatalf("web: CaseInsensitive must be called before any " +
+			"routes are added")
+	}
+	rt.fold = true
+	rt.setMachine(nil)
+}
+
+// foldLiteralPrefix returns the portion of a case-insensitive literal which
+// can be compared case-sensitively: everything before its first letter.
+func foldLiteralPrefix(s string) string {
+	for i := 0; i < len(s); i++ {
+		if isASCIILetter(s[i]) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func isASCIILetter(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// lowerASCII lowercases the ASCII letters in s. Unlike strings.ToLower, it
+// never changes the length of the string, so indexes into the result are also
+// valid indexes into s.
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if 'A' <= s[i] && s[i] <= 'Z' {
+			b := []byte(s)
+			for ; i < len(b); i++ {
+				if 'A' <= b[i] && b[i] <= 'Z' {
+					b[i] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
+
+// equalFoldASCII reports whether s and t are equal, ignoring the case of ASCII
+// letters.
+func equalFoldASCII(s, t string) bool {
+	if len(s) != len(t) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		a, b := s[i], t[i]
+		if 'A' <= a && a <= 'Z' {
+			a += 'a' - 'A'
+		}
+		if 'A' <= b && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		if a != b {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestLowerASCII(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in, out string
+	}{
+		{"/about", "/about"},
+		{"/About/US", "/about/us"},
+		{"/Über", "/Über"},
+	}
+	for _, test := range tests {
+		if actual := lowerASCII(test.in); actual != test.out {
+			t.Errorf("lowerASCII(%q): expected %q, got %q", test.in,
+				test.out, actual)
+		}
+		if !equalFoldASCII(test.in, test.out) {
+			t.Errorf("Expected %q and %q to be equal", test.in,
+				test.out)
+		}
+	}
+	if equalFoldASCII("/about", "/abut") {
+		t.Error("Expected /about and /abut to differ")
+	}
+}
+
+func foldMux(radix bool, ch chan string) *Mux {
+	m := New()
+	m.CaseInsensitive()
+	m.RadixRouting(radix)
+	m.Get("/About", chHandler(ch, "about"))
+	m.Get("/users/:name/Posts", func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- "posts " + c.URLParams["name"]
+	})
+	m.Get("/Static/*", chHandler(ch, "static"))
+	m.Get(regexp.MustCompile(`^/Greets/\d+$`), chHandler(ch, "greets"))
+	m.NotFound(chHandler(ch, "404"))
+	return m
+}
+
+func TestCaseInsensitiveMux(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		path, expected string
+	}{
+		{"/about", "about"},
+		{"/ABOUT", "about"},
+		{"/about/", "404"},
+		{"/USERS/Carl/posts", "posts Carl"},
+		{"/static/app.JS", "static"},
+		{"/Greets/12", "greets"},
+		// Regular expressions are still case sensitive
+		{"/greets/12", "404"},
+	}
+	for _, radix := range []bool{false, true} {
+		ch := make(chan string, 1)
+		m := foldMux(radix, ch)
+		for _, test := range tests {
+			r, _ := http.NewRequest("GET", test.path, nil)
+			m.ServeHTTP(httptest.NewRecorder(), r)
+			if actual := <-ch; actual != test.expected {
+				t.Errorf("%s (radix=%v): expected %q, got %q",
+					test.path, radix, test.expected, actual)
+			}
+		}
+	}
+}
+
+func TestCaseInsensitivePattern(t *testing.T) {
+	t.Parallel()
+	for _, radix := range []bool{false, true} {
+		ch := make(chan string, 1)
+		m := New()
+		m.RadixRouting(radix)
+		m.Get("/about", chHandler(ch, "exact"))
+		m.Get(CaseInsensitive("/about"), chHandler(ch, "folded"))
+		m.Get("/Contact", chHandler(ch, "contact"))
+		m.NotFound(chHandler(ch, "404"))
+
+		tests := []struct {
+			path, expected string
+		}{
+			{"/about", "exact"},
+			{"/About", "folded"},
+			{"/Contact", "contact"},
+			{"/contact", "404"},
+		}
+		for _, test := range tests {
+			r, _ := http.NewRequest("GET", test.path, nil)
+			m.ServeHTTP(httptest.NewRecorder(), r)
+			if actual := <-ch; actual != test.expected {
+				t.Errorf("%s (radix=%v): expected %q, got %q",
+					test.path, radix, test.expected, actual)
+			}
+		}
+	}
+}
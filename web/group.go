@@ -0,0 +1,208 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+/*
+Group is a collection of routes which share a common path prefix and middleware
+stack. Groups are created with Mux.Group.
+
+Routes added to a Group are added directly to the Mux that created it, and
+behave exactly like any other route on that Mux: they are matched in the order
+they were added, alongside routes that were added to the Mux itself or to other
+Groups. This means that, unlike when mounting a separate Mux under a prefix,
+routing information like the Match and ValidMethodsKey reflect every route in
+the Mux.
+
+The prefix is prepended to the patterns of all routes added to the Group. String
+patterns are simply concatenated with the prefix, so that a Group with prefix
+"/admin" will add the pattern "/admin/stats" when given "/stats". Other patterns
+(including regular expressions) are matched against the portion of the path
+following the prefix.
+
+The Group's middleware is run after the Mux's middleware, and only for requests
+which are routed to the Group's routes. Since routing has already taken place,
+URLParams will already be populated when the Group's middleware is run.
+*/
+type Group struct {
+	rt         *router
+	prefix     string
+	middleware []MiddlewareType
+}
+
+// Group returns a Group which adds routes to this Mux under the given path
+// prefix, passing requests routed to them through the given middleware.
+func (m *Mux) Group(prefix string, middleware ...MiddlewareType) *Group {
+	return &Group{
+		rt:         &m.rt,
+		prefix:     strings.TrimSuffix(prefix, "/"),
+		middleware: middleware,
+	}
+}
+
+// Group returns a nested Group. Its prefix is appended to this Group's prefix,
+// and its middleware is run after (inside) this Group's middleware.
+func (g *Group) Group(prefix string, middleware ...MiddlewareType) *Group {
+	mw := make([]MiddlewareType, 0, len(g.middleware)+len(middleware))
+	mw = append(mw, g.middleware...)
+	mw = append(mw, middleware...)
+	return &Group{
+		rt:         g.rt,
+		prefix:     g.prefix + strings.TrimSuffix(prefix, "/"),
+		middleware: mw,
+	}
+}
+
+func (g *Group) handleUntyped(p PatternType, m method, h HandlerType, middleware []MiddlewareType) *Route {
+	var pattern Pattern
+	switch v := p.(type) {
+	case string:
+		pattern = parseString(g.prefix + v)
+	case *regexp.Regexp, Pattern:
+		pattern = prefixPattern{
+			prefix: g.prefix,
+			pat:    ParsePattern(v),
+			raw:    prefixRaw(g.prefix, v),
+		}
+	default:
+		pattern = ParsePattern(v)
+	}
+
+	return g.rt.handle(pattern, m, parseHandler(h), g.middleware, middleware)
+}
+
+// Handle dispatches to the given handler when the pattern matches, regardless
+// of HTTP method. See the documentation for Mux.Handle for more information.
+func (g *Group) Handle(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mALL, handler, middleware)
+}
+
+// Connect dispatches to the given handler when the pattern matches and the HTTP
+// method is CONNECT.
+func (g *Group) Connect(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mCONNECT, handler, middleware)
+}
+
+// Delete dispatches to the given handler when the pattern matches and the HTTP
+// method is DELETE.
+func (g *Group) Delete(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mDELETE, handler, middleware)
+}
+
+// Get dispatches to the given handler when the pattern matches and the HTTP
+// method is GET. As with Mux.Get, GET handlers also serve HEAD requests.
+func (g *Group) Get(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mGET|mHEAD, handler, middleware)
+}
+
+// Head dispatches to the given handler when the pattern matches and the HTTP
+// method is HEAD.
+func (g *Group) Head(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mHEAD, handler, middleware)
+}
+
+// Options dispatches to the given handler when the pattern matches and the HTTP
+// method is OPTIONS.
+func (g *Group) Options(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mOPTIONS, handler, middleware)
+}
+
+// Patch dispatches to the given handler when the pattern matches and the HTTP
+// method is PATCH.
+func (g *Group) Patch(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mPATCH, handler, middleware)
+}
+
+// Post dispatches to the given handler when the pattern matches and the HTTP
+// method is POST.
+func (g *Group) Post(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mPOST, handler, middleware)
+}
+
+// Put dispatches to the given handler when the pattern matches and the HTTP
+// method is PUT.
+func (g *Group) Put(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mPUT, handler, middleware)
+}
+
+// Trace dispatches to the given handler when the pattern matches and the HTTP
+// method is TRACE.
+func (g *Group) Trace(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, mTRACE, handler, middleware)
+}
+
+// Method dispatches to the given handler when the pattern matches and the HTTP
+// method is the given one. See the documentation for Mux.Method for more
+// information.
+func (g *Group) Method(method string, pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return g.handleUntyped(pattern, g.rt.method(method), handler,
+		middleware)
+}
+
+// prefixPattern matches requests whose path begins with the given prefix, and
+// whose remaining path matches the given Pattern.
+type prefixPattern struct {
+	prefix string
+	pat    Pattern
+	// raw is reported as the route's PatternType (see Match.RawPattern).
+	raw PatternType
+}
+
+// prefixRaw returns the PatternType reported for a Pattern added to a Group.
+// Regular expressions are rewritten to include the Group's prefix, while other
+// Patterns are reported as they were given.
+func prefixRaw(prefix string, p PatternType) PatternType {
+	re, ok := p.(*regexp.Regexp)
+	if !ok {
+		return p
+	}
+	src := strings.TrimPrefix(re.String(), "^")
+	return regexp.MustCompile("^" + regexp.QuoteMeta(prefix) + src)
+}
+
+func (p prefixPattern) Prefix() string {
+	return p.prefix + p.pat.Prefix()
+}
+func (p prefixPattern) Match(r *http.Request, c *C) bool {
+	sr, ok := p.strip(r)
+	return ok && p.pat.Match(sr, c)
+}
+func (p prefixPattern) Run(r *http.Request, c *C) {
+	if sr, ok := p.strip(r); ok {
+		p.pat.Run(sr, c)
+	}
+}
+
+// strip returns a shallow copy of the request with the prefix removed from its
+// path. Patterns aren't allowed to modify the request they're given, so we
+// can't just modify it in place.
+func (p prefixPattern) strip(r *http.Request) (*http.Request, bool) {
+	if !strings.HasPrefix(r.URL.Path, p.prefix) {
+		return nil, false
+	}
+	sr := *r
+	u := *r.URL
+	u.Path = u.Path[len(p.prefix):]
+	sr.URL = &u
+	return &sr, true
+}
+
+func (p prefixPattern) buildURL(params map[string]string) (string, error) {
+	ub, ok := p.pat.(urlBuilder)
+	if !ok {
+		return "", fmt.Errorf("cannot build URLs for %v", p.pat)
+	}
+	path, err := ub.buildURL(params)
+	if err != nil {
+		return "", err
+	}
+	return escapePath(p.prefix) + path, nil
+}
+
+func (p prefixPattern) String() string {
+	return fmt.Sprintf("prefixPattern(%q, %v)", p.prefix, p.pat)
+}
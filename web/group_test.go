@@ -0,0 +1,129 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 2)
+
+	tag := func(c *C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ch <- "middleware " + c.URLParams["name"]
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+
+	g := m.Group("/admin/", tag)
+	g.Get("/", chHandler(ch, "root"))
+	g.Get("/users/:name", chHandler(ch, "user"))
+	g.Get(regexp.MustCompile(`^/greets/(?P<name>\d+)$`), chHandler(ch, "greet"))
+	m.Get("/users/:name", chHandler(ch, "public"))
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"/admin/", []string{"middleware ", "root"}},
+		{"/admin/users/carl", []string{"middleware carl", "user"}},
+		{"/admin/greets/12", []string{"middleware 12", "greet"}},
+		{"/users/carl", []string{"public"}},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest("GET", test.path, nil)
+		m.ServeHTTP(httptest.NewRecorder(), r)
+		actual := make([]string, len(test.expected))
+		for i := range actual {
+			actual[i] = <-ch
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("For %q, expected %v, got %v", test.path,
+				test.expected, actual)
+		}
+	}
+}
+
+func TestNestedGroup(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 3)
+
+	outer := m.Group("/api", chanWare(ch, "outer"))
+	inner := outer.Group("/v1", chanWare(ch, "inner"))
+	inner.Post("/widgets", chHandler(ch, "widgets"))
+
+	r, _ := http.NewRequest("POST", "/api/v1/widgets", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	assertOrder(t, ch, "outer", "inner", "widgets")
+}
+
+func TestGroupSharesRouting(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan Match, 1)
+	m.Use(m.Router)
+	m.Use(func(c *C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ch <- GetMatch(*c)
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	})
+	methods := make(chan []string, 1)
+	m.MethodNotAllowed(func(c C, w http.ResponseWriter, r *http.Request) {
+		methods <- c.Env[ValidMethodsKey].([]string)
+	})
+
+	g := m.Group("/admin", chanWare(make(chan string, 1), "unused"))
+	g.Get("/stats", http.NotFound)
+	g.Put("/stats", http.NotFound)
+
+	r, _ := http.NewRequest("GET", "/admin/stats", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	if rp := (<-ch).RawPattern(); rp != "/admin/stats" {
+		t.Errorf("Expected RawPattern /admin/stats, got %v", rp)
+	}
+
+	r, _ = http.NewRequest("POST", "/admin/stats", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	<-ch
+	expected := []string{"GET", "HEAD", "PUT"}
+	if actual := <-methods; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected valid methods %v, got %v", expected, actual)
+	}
+}
+
+func TestGroupURL(t *testing.T) {
+	t.Parallel()
+	m := New()
+	g := m.Group("/greets")
+	g.Get(regexp.MustCompile(`^/(?P<id>\d+)$`), http.NotFound).Name("greet")
+	g.Get("/:id/edit", http.NotFound).Name("edit")
+
+	if url, err := m.URL("greet", map[string]string{"id": "1"}); err != nil || url != "/greets/1" {
+		t.Errorf("Expected /greets/1, got %q (%v)", url, err)
+	}
+	if url, err := m.URL("edit", map[string]string{"id": "1"}); err != nil || url != "/greets/1/edit" {
+		t.Errorf("Expected /greets/1/edit, got %q (%v)", url, err)
+	}
+}
+
+func TestGroupRouteMiddleware(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 3)
+
+	g := m.Group("/admin", chanWare(ch, "group"))
+	g.Get("/stats", chHandler(ch, "stats"), chanWare(ch, "route"))
+
+	r, _ := http.NewRequest("GET", "/admin/stats", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	assertOrder(t, ch, "group", "route", "stats")
+}
@@ -0,0 +1,153 @@
+package web
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type hostPattern struct {
+	raw    string
+	scheme string
+	labels []string
+	port   string
+	// Whether the leftmost label is a "*"
+	wildcard bool
+	path     Pattern
+}
+
+/*
+Host returns a Pattern which matches requests whose Host header matches the
+given host pattern, and whose path matches the given path pattern (which may be
+of any of the types described in the documentation for PatternType).
+
+Host patterns are matched label by label (i.e., split on "."), and are matched
+case-insensitively. In addition to literal labels, the following syntax is
+recognized:
+  - a label starting with a colon will match any single label, binding
+    the label to the given name in URLParams, alongside any parameters
+    bound by the path pattern. e.g., ":tenant.example.com" will match
+    "acme.example.com", binding "tenant" to "acme".
+  - a leftmost label of "*" will match one or more labels. e.g.,
+    "*.example.com" will match both "www.example.com" and
+    "a.b.example.com", but not "example.com".
+  - a pattern may end with a port number, as in "example.com:8080", in
+    which case the request must have been made to that port. Otherwise,
+    requests to any port will match.
+  - a pattern may begin with a scheme, as in "https://example.com", in
+    which case only requests with that scheme will match. Requests are
+    considered to use the "https" scheme if they were received over TLS,
+    unless the request URL contains an explicit scheme.
+
+For example, Host(":tenant.example.com", "/users/:id") will match a request for
+"http://acme.example.com/users/123", binding "tenant" to "acme" and "id" to
+"123".
+*/
+func Host(host string, path PatternType) Pattern {
+	hp := hostPattern{raw: host, path: ParsePattern(path)}
+	if i := strings.Index(host, "://"); i != -1 {
+		hp.scheme = strings.ToLower(host[:i])
+		host = host[i+3:]
+	}
+	// A colon which doesn't begin a label separates the port.
+	if i := strings.LastIndex(host, ":"); i > 0 && host[i-1] != '.' {
+		hp.port = host[i+1:]
+		host = host[:i]
+	}
+	hp.labels = strings.Split(strings.ToLower(host), ".")
+	if hp.labels[0] == "*" {
+		hp.wildcard = true
+		hp.labels = hp.labels[1:]
+	}
+	return hp
+}
+
+func (h hostPattern) Prefix() string {
+	return h.path.Prefix()
+}
+func (h hostPattern) Match(r *http.Request, c *C) bool {
+	return h.matchHost(r, nil) && h.path.Match(r, c)
+}
+func (h hostPattern) Run(r *http.Request, c *C) {
+	params := make(map[string]string)
+	h.matchHost(r, params)
+	h.path.Run(r, c)
+	if len(params) == 0 {
+		return
+	}
+	if c.URLParams == nil {
+		c.URLParams = params
+	} else {
+		for k, v := range params {
+			c.URLParams[k] = v
+		}
+	}
+}
+
+func requestScheme(r *http.Request) string {
+	if r.URL.Scheme != "" {
+		return strings.ToLower(r.URL.Scheme)
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// matchHost returns true if the request's host matches, binding the values of
+// any named labels into params if it is non-nil.
+func (h hostPattern) matchHost(r *http.Request, params map[string]string) bool {
+	if h.scheme != "" && requestScheme(r) != h.scheme {
+		return false
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	port := ""
+	if hh, p, err := net.SplitHostPort(host); err == nil {
+		host, port = hh, p
+	}
+	if h.port != "" && port != h.port {
+		return false
+	}
+
+	labels := strings.Split(strings.ToLower(host), ".")
+	if h.wildcard {
+		if len(labels) <= len(h.labels) {
+			return false
+		}
+		labels = labels[len(labels)-len(h.labels):]
+	} else if len(labels) != len(h.labels) {
+		return false
+	}
+
+	for i, pl := range h.labels {
+		l := labels[i]
+		if strings.HasPrefix(pl, ":") {
+			if l == "" {
+				return false
+			}
+			if params != nil {
+				params[pl[1:]] = l
+			}
+		} else if pl != l {
+			return false
+		}
+	}
+	return true
+}
+
+func (h hostPattern) buildURL(params map[string]string) (string, error) {
+	ub, ok := h.path.(urlBuilder)
+	if !ok {
+		return "", fmt.Errorf("cannot build URLs for %v", h.path)
+	}
+	return ub.buildURL(params)
+}
+
+func (h hostPattern) String() string {
+	return fmt.Sprintf("hostPattern(%q, %v)", h.raw, h.path)
+}
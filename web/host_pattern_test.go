@@ -0,0 +1,98 @@
+package web
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func hostReq(url, host string) *http.Request {
+	r, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		panic(err)
+	}
+	r.Host = host
+	return r
+}
+
+var hostTests = []struct {
+	host   string
+	path   PatternType
+	r      *http.Request
+	match  bool
+	params map[string]string
+}{
+	{"example.com", "/", hostReq("/", "example.com"), true, nil},
+	{"example.com", "/", hostReq("/", "EXAMPLE.com:8080"), true, nil},
+	{"example.com", "/", hostReq("/", "www.example.com"), false, nil},
+	{"example.com", "/", hostReq("/a", "example.com"), false, nil},
+	{":tenant.example.com", "/users/:id",
+		hostReq("/users/123", "acme.example.com"), true,
+		map[string]string{"tenant": "acme", "id": "123"}},
+	{":tenant.example.com", "/users/:id",
+		hostReq("/users/123", "example.com"), false, nil},
+	{":tenant.example.com", "/users/:id",
+		hostReq("/users/123", "a.b.example.com"), false, nil},
+	{"*.example.com", "/", hostReq("/", "a.b.example.com"), true, nil},
+	{"*.example.com", "/", hostReq("/", "example.com"), false, nil},
+	{"example.com:8080", "/", hostReq("/", "example.com:8080"), true, nil},
+	{"example.com:8080", "/", hostReq("/", "example.com"), false, nil},
+	{"https://example.com", "/", hostReq("/", "example.com"), false, nil},
+	{"http://example.com", "/", hostReq("/", "example.com"), true, nil},
+	{"https://example.com", "/",
+		hostReq("https://example.com/", "example.com"), true, nil},
+}
+
+func TestHostPattern(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range hostTests {
+		p := Host(test.host, test.path)
+		c := &C{}
+		if match := p.Match(test.r, c); match != test.match {
+			t.Errorf("Expected %v to match %s%s: %v", p,
+				test.r.Host, test.r.URL.Path, test.match)
+			continue
+		}
+		if !test.match {
+			continue
+		}
+		p.Run(test.r, c)
+		if !reflect.DeepEqual(c.URLParams, test.params) {
+			t.Errorf("Expected %v for %v, got %v", test.params, p,
+				c.URLParams)
+		}
+	}
+}
+
+func TestHostPatternTLS(t *testing.T) {
+	t.Parallel()
+
+	r := hostReq("/", "example.com")
+	r.TLS = &tls.ConnectionState{}
+	if !Host("https://example.com", "/").Match(r, &C{}) {
+		t.Error("Expected a TLS request to match an https pattern")
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 1)
+
+	m.Get(Host(":tenant.example.com", "/"), func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- c.URLParams["tenant"]
+	})
+	m.Get("/", chHandler(ch, "default"))
+
+	m.ServeHTTP(httptest.NewRecorder(), hostReq("/", "acme.example.com"))
+	if v := <-ch; v != "acme" {
+		t.Errorf("Expected acme, got %q", v)
+	}
+	m.ServeHTTP(httptest.NewRecorder(), hostReq("/", "example.org"))
+	if v := <-ch; v != "default" {
+		t.Errorf("Expected default, got %q", v)
+	}
+}
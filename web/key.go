@@ -0,0 +1,46 @@
+// +build !go1.18
+
+package web
+
+// Before Go 1.18, there are no typed Keys, so only the plain keys are used.
+
+func envMatch(c C) (Match, bool) {
+	m, ok := c.Env[MatchKey].(Match)
+	return m, ok
+}
+
+func setEnvMatch(c *C, m Match) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[MatchKey] = m
+}
+
+func envValidMethods(c C) ([]string, bool) {
+	methods, ok := c.Env[ValidMethodsKey].([]string)
+	return methods, ok
+}
+
+func setEnvValidMethods(c *C, methods []string) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[ValidMethodsKey] = methods
+}
+
+func envPredicateStatus(c C) (int, bool) {
+	status, ok := c.Env[PredicateStatusKey].(int)
+	return status, ok
+}
+
+func setEnvPredicateStatus(c *C, status int) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[PredicateStatusKey] = status
+}
+
+func envMountPrefix(c C) (string, bool) {
+	prefix, ok := c.Env[MountPrefixKey].(string)
+	return prefix, ok
+}
@@ -0,0 +1,127 @@
+// +build go1.18
+
+package web
+
+// Key is a typed key for values stored in C.Env. Keys are compared by identity
+// rather than by name, so Keys belonging to different packages never collide
+// with each other (or with plain keys like strings), and values retrieved
+// using Get are already of the right type.
+//
+// Keys should be created once, typically as package-level variables:
+//
+//	var userKey = web.NewKey[*User]("user")
+//
+//	func Auth(c *web.C, h http.Handler) http.Handler {
+//		fn := func(w http.ResponseWriter, r *http.Request) {
+//			userKey.Set(c, lookupUser(r))
+//			h.ServeHTTP(w, r)
+//		}
+//		return http.HandlerFunc(fn)
+//	}
+//
+//	func Hello(c web.C, w http.ResponseWriter, r *http.Request) {
+//		if user, ok := userKey.Get(c); ok {
+//			fmt.Fprintf(w, "Hello, %s!", user.Name)
+//		}
+//	}
+type Key[T any] struct {
+	name   string
+	legacy interface{}
+}
+
+// NewKey returns a new Key for values of type T. The name is only used for
+// debugging.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// NewLegacyKey returns a new Key whose values are stored under the given plain
+// Env key, for packages migrating away from plain keys. The plain key is
+// authoritative: code which reads, writes, or deletes it directly continues to
+// work, and Get reports no value if the plain key is missing or holds a value
+// which is not of type T.
+func NewLegacyKey[T any](name string, legacy interface{}) *Key[T] {
+	return &Key[T]{name: name, legacy: legacy}
+}
+
+// Get returns the value stored under the key in the given context's
+// environment, and whether there was one.
+func (k *Key[T]) Get(c C) (T, bool) {
+	v, ok := c.Env[k.slot()].(T)
+	return v, ok
+}
+
+// Set stores a value under the key in the given context's environment,
+// allocating the environment if necessary.
+func (k *Key[T]) Set(c *C, v T) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[k.slot()] = v
+}
+
+// Delete removes the value stored under the key from the given context's
+// environment, if any.
+func (k *Key[T]) Delete(c *C) {
+	delete(c.Env, k.slot())
+}
+
+// slot returns the Env key values are actually stored under.
+func (k *Key[T]) slot() interface{} {
+	if k.legacy != nil {
+		return k.legacy
+	}
+	return k
+}
+
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+var (
+	// MatchEnvKey is a typed Key for the Match stored by Mux.Router under
+	// MatchKey.
+	MatchEnvKey = NewLegacyKey[Match]("goji.web.Match", MatchKey)
+	// ValidMethodsEnvKey is a typed Key for the list of valid methods given
+	// to the NotFound and MethodNotAllowed handlers under
+	// ValidMethodsKey.
+	ValidMethodsEnvKey = NewLegacyKey[[]string]("goji.web.ValidMethods",
+		ValidMethodsKey)
+	// PredicateStatusEnvKey is a typed Key for the status code of the first
+	// failed Predicate, given to the NotFound handler under
+	// PredicateStatusKey.
+	PredicateStatusEnvKey = NewLegacyKey[int]("goji.web.PredicateStatus",
+		PredicateStatusKey)
+	// MountPrefixEnvKey is a typed Key for the path prefix stripped before
+	// a request was passed to a nested Mux, under MountPrefixKey.
+	MountPrefixEnvKey = NewLegacyKey[string]("goji.web.MountPrefix",
+		MountPrefixKey)
+)
+
+func envMatch(c C) (Match, bool) {
+	return MatchEnvKey.Get(c)
+}
+
+func setEnvMatch(c *C, m Match) {
+	MatchEnvKey.Set(c, m)
+}
+
+func envValidMethods(c C) ([]string, bool) {
+	return ValidMethodsEnvKey.Get(c)
+}
+
+func setEnvValidMethods(c *C, methods []string) {
+	ValidMethodsEnvKey.Set(c, methods)
+}
+
+func envPredicateStatus(c C) (int, bool) {
+	return PredicateStatusEnvKey.Get(c)
+}
+
+func setEnvPredicateStatus(c *C, status int) {
+	PredicateStatusEnvKey.Set(c, status)
+}
+
+func envMountPrefix(c C) (string, bool) {
+	return MountPrefixEnvKey.Get(c)
+}
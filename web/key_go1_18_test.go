@@ -0,0 +1,143 @@
+// +build go1.18
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	a := NewKey[int]("n")
+	b := NewKey[int]("n")
+	var c C
+
+	if _, ok := a.Get(c); ok {
+		t.Error("Expected no value in an empty context")
+	}
+	a.Set(&c, 1)
+	b.Set(&c, 2)
+	c.Env["n"] = "three"
+	if v, ok := a.Get(c); !ok || v != 1 {
+		t.Errorf("Expected 1, got %v", v)
+	}
+	if v, ok := b.Get(c); !ok || v != 2 {
+		t.Errorf("Expected 2, got %v", v)
+	}
+	a.Delete(&c)
+	if _, ok := a.Get(c); ok {
+		t.Error("Expected value to be deleted")
+	}
+	if a.String() != "n" {
+		t.Errorf("Expected name n, got %q", a.String())
+	}
+}
+
+func TestLegacyKey(t *testing.T) {
+	t.Parallel()
+	k := NewLegacyKey[string]("k", "legacy")
+	var c C
+
+	k.Set(&c, "a")
+	if c.Env["legacy"] != "a" {
+		t.Errorf("Expected legacy key to be set, got %v", c.Env)
+	}
+	c.Env["legacy"] = "b"
+	if v, _ := k.Get(c); v != "b" {
+		t.Errorf("Expected legacy value b, got %q", v)
+	}
+	// The legacy key is authoritative, even if it has the wrong type
+	c.Env["legacy"] = 5
+	if v, ok := k.Get(c); ok {
+		t.Errorf("Expected no value, got %q", v)
+	}
+	k.Set(&c, "a")
+	delete(c.Env, "legacy")
+	if v, ok := k.Get(c); ok {
+		t.Errorf("Expected deleted legacy key to be absent, got %q", v)
+	}
+	k.Set(&c, "a")
+	k.Delete(&c)
+	if _, ok := c.Env["legacy"]; ok {
+		t.Error("Expected legacy key to be deleted")
+	}
+}
+
+func TestDeletedMatchKey(t *testing.T) {
+	t.Parallel()
+	inner := New()
+	inner.Get("/x", http.NotFound)
+	outer := New()
+	outer.Use(outer.Router)
+	outer.Use(func(c *C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			delete(c.Env, MatchKey)
+			if match := GetMatch(*c); match.Handler != nil {
+				t.Errorf("Expected no Match, got %v", match)
+			}
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	})
+	outer.Get("/x", inner)
+
+	r, _ := http.NewRequest("GET", "/x", nil)
+	w := httptest.NewRecorder()
+	outer.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound || w.Body.String() != "404 page not found\n" {
+		t.Errorf("Expected inner route to be served, got %d", w.Code)
+	}
+}
+
+func TestBuiltinKeys(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan C, 1)
+	m.Use(m.Router)
+	m.Use(func(c *C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ch <- *c
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	})
+	m.Get("/a", http.NotFound)
+
+	r, _ := http.NewRequest("POST", "/a", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	c := <-ch
+	if match, ok := MatchEnvKey.Get(c); !ok || match.Handler == nil {
+		t.Errorf("Expected a Match, got %v", match)
+	}
+	if _, ok := c.Env[MatchKey].(Match); !ok {
+		t.Error("Expected a Match under MatchKey")
+	}
+	methods, ok := ValidMethodsEnvKey.Get(c)
+	if !ok || len(methods) != 2 {
+		t.Errorf("Expected valid methods, got %v", methods)
+	}
+}
+
+func TestPredicateStatusKey(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan C, 1)
+	m.Get("/a", http.NotFound).Where(Accept("application/json"))
+	m.NotFound(func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- c
+	})
+
+	r, _ := http.NewRequest("GET", "/a", nil)
+	r.Header.Set("Accept", "text/html")
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	c := <-ch
+	status, ok := PredicateStatusEnvKey.Get(c)
+	if !ok || status != http.StatusNotAcceptable {
+		t.Errorf("Expected status 406, got %d", status)
+	}
+	if c.Env[PredicateStatusKey] != http.StatusNotAcceptable {
+		t.Error("Expected status under PredicateStatusKey")
+	}
+}
@@ -19,32 +19,35 @@ type Match struct {
 	Pattern Pattern
 	// The Handler corresponding to the matched pattern.
 	Handler Handler
+	// Name is the name of the route that matched (see Route.Name), or the
+	// empty string if it has none.
+	Name string
+	// Meta is the metadata attached to the route that matched (see
+	// Route.Meta). It is shared with the route, and must not be modified.
+	Meta map[interface{}]interface{}
 }
 
 // GetMatch returns the Match stored in the Goji environment, or an empty Match
 // if none exists (valid Matches always have a Handler property).
 func GetMatch(c C) Match {
-	if c.Env == nil {
-		return Match{}
-	}
-	mi, ok := c.Env[MatchKey]
-	if !ok {
-		return Match{}
-	}
-	if m, ok := mi.(Match); ok {
-		return m
-	}
-	return Match{}
+	m, _ := envMatch(c)
+	return m
 }
 
 // RawPattern returns the PatternType that was originally passed to ParsePattern
-// or any of the HTTP method functions (Get, Post, etc.).
+// or any of the HTTP method functions (Get, Post, etc.). For regular expressions
+// added through a Group, an equivalent regular expression which includes the
+// Group's prefix is returned.
 func (m Match) RawPattern() PatternType {
 	switch v := m.Pattern.(type) {
 	case regexpPattern:
 		return v.re
 	case stringPattern:
 		return v.raw
+	case optionalPattern:
+		return v.raw
+	case prefixPattern:
+		return v.raw
 	default:
 		return v
 	}
@@ -60,6 +63,8 @@ func (m Match) RawHandler() HandlerType {
 		return v.fn
 	case netHTTPHandlerFuncWrap:
 		return v.fn
+	case stackHandler:
+		return Match{Handler: v.h}.RawHandler()
 	default:
 		return v
 	}
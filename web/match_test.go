@@ -46,5 +46,10 @@ func TestRawHandler(t *testing.T) {
 		if rh := m.RawHandler(); !funcEqual(rh, h) {
 			t.Errorf("got %#v, expected %#v", rh, h)
 		}
+
+		m = Match{Handler: newStackHandler(parseHandler(h), nil)}
+		if rh := m.RawHandler(); !funcEqual(rh, h) {
+			t.Errorf("got %#v, expected %#v", rh, h)
+		}
 	}
 }
@@ -50,6 +50,51 @@ func (s *cStack) ServeHTTPC(c C, w http.ResponseWriter, r *http.Request) {
 	s.m.ServeHTTP(w, r)
 }
 
+// handlerRouter adapts a Handler for use as the innermost layer of a
+// middleware stack.
+type handlerRouter struct {
+	h Handler
+}
+
+func (hr handlerRouter) route(c *C, w http.ResponseWriter, r *http.Request) {
+	hr.h.ServeHTTPC(*c, w, r)
+}
+
+// stackHandler is a Handler which passes requests through a middleware stack
+// before handing them to another Handler. It uses the same pool of cached
+// stacks as a Mux's middleware, so it is cheap to use on a per-route basis.
+type stackHandler struct {
+	ms *mStack
+	h  Handler
+}
+
+func newStackHandler(h Handler, middleware []MiddlewareType) stackHandler {
+	ms := mStack{
+		stack:  make([]mLayer, 0, len(middleware)),
+		pool:   makeCPool(),
+		router: handlerRouter{h},
+	}
+	for _, mw := range middleware {
+		ms.appendLayer(mw)
+	}
+	return stackHandler{ms: &ms, h: h}
+}
+
+// withMiddleware wraps the given Handler in a stackHandler, unless there is no
+// middleware to wrap it with.
+func withMiddleware(h Handler, middleware []MiddlewareType) Handler {
+	if len(middleware) == 0 {
+		return h
+	}
+	return newStackHandler(h, middleware)
+}
+
+func (s stackHandler) ServeHTTPC(c C, w http.ResponseWriter, r *http.Request) {
+	cs := s.ms.alloc()
+	cs.ServeHTTPC(c, w, r)
+	s.ms.release(cs)
+}
+
 const unknownMiddleware = `Unknown middleware type %T. See http://godoc.org/github.com/zenazn/goji/web#MiddlewareType for a list of acceptable types.`
 
 func (m *mStack) appendLayer(fn interface{}) {
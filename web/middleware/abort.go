@@ -0,0 +1,8 @@
+// +build !go1.8
+
+package middleware
+
+// Before Go 1.8, there is no http.ErrAbortHandler.
+func isAbort(err interface{}) bool {
+	return false
+}
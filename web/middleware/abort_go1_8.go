@@ -0,0 +1,11 @@
+// +build go1.8
+
+package middleware
+
+import "net/http"
+
+// isAbort reports whether the recovered value is the sentinel net/http uses to
+// abort a response, which should be allowed to propagate.
+func isAbort(err interface{}) bool {
+	return err == http.ErrAbortHandler
+}
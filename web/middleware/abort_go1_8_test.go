@@ -0,0 +1,32 @@
+// +build go1.8
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecovererAbort(t *testing.T) {
+	t.Parallel()
+	var tracker fakeTracker
+	m := recovererMux(RecovererOptions{OnPanic: tracker.report})
+	m.Get("/abort", func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("Expected ErrAbortHandler to be re-raised, got %v",
+				err)
+		}
+		if len(tracker.errs) != 0 {
+			t.Errorf("Expected abort not to be reported, got %v",
+				tracker.errs)
+		}
+	}()
+	r, _ := http.NewRequest("GET", "/abort", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	t.Error("Expected panic")
+}
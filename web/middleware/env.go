@@ -0,0 +1,62 @@
+// +build !go1.18
+
+package middleware
+
+import (
+	"net/url"
+
+	"github.com/zenazn/goji/web"
+)
+
+// Before Go 1.18, there are no typed Keys, so only the plain keys are used.
+
+func getReqID(c web.C) (string, bool) {
+	id, ok := c.Env[RequestIDKey].(string)
+	return id, ok
+}
+
+func setReqID(c *web.C, id string) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[RequestIDKey] = id
+}
+
+func setURLQuery(c *web.C, q url.Values) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[URLQueryKey] = q
+}
+
+func getValidMethods(c web.C) []string {
+	methods, _ := c.Env[web.ValidMethodsKey].([]string)
+	return methods
+}
+
+func setMatch(c *web.C, m web.Match) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[web.MatchKey] = m
+}
+
+func deleteMatch(c *web.C) {
+	delete(c.Env, web.MatchKey)
+}
+
+func getMountPrefix(c web.C) (string, bool) {
+	prefix, ok := c.Env[web.MountPrefixKey].(string)
+	return prefix, ok
+}
+
+func setMountPrefix(c *web.C, prefix string) {
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{})
+	}
+	c.Env[web.MountPrefixKey] = prefix
+}
+
+func deleteMountPrefix(c *web.C) {
+	delete(c.Env, web.MountPrefixKey)
+}
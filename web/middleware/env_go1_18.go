@@ -0,0 +1,57 @@
+// +build go1.18
+
+package middleware
+
+import (
+	"net/url"
+
+	"github.com/zenazn/goji/web"
+)
+
+var (
+	// RequestIDEnvKey is a typed Key for the request ID set by RequestID
+	// under RequestIDKey.
+	RequestIDEnvKey = web.NewLegacyKey[string]("goji.middleware.RequestID",
+		RequestIDKey)
+	// URLQueryEnvKey is a typed Key for the query parameters parsed by
+	// URLQuery under URLQueryKey.
+	URLQueryEnvKey = web.NewLegacyKey[url.Values]("goji.middleware.URLQuery",
+		URLQueryKey)
+)
+
+func getReqID(c web.C) (string, bool) {
+	return RequestIDEnvKey.Get(c)
+}
+
+func setReqID(c *web.C, id string) {
+	RequestIDEnvKey.Set(c, id)
+}
+
+func setURLQuery(c *web.C, q url.Values) {
+	URLQueryEnvKey.Set(c, q)
+}
+
+func getValidMethods(c web.C) []string {
+	methods, _ := web.ValidMethodsEnvKey.Get(c)
+	return methods
+}
+
+func setMatch(c *web.C, m web.Match) {
+	web.MatchEnvKey.Set(c, m)
+}
+
+func deleteMatch(c *web.C) {
+	web.MatchEnvKey.Delete(c)
+}
+
+func getMountPrefix(c web.C) (string, bool) {
+	return web.MountPrefixEnvKey.Get(c)
+}
+
+func setMountPrefix(c *web.C, prefix string) {
+	web.MountPrefixEnvKey.Set(c, prefix)
+}
+
+func deleteMountPrefix(c *web.C) {
+	web.MountPrefixEnvKey.Delete(c)
+}
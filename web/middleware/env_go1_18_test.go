@@ -0,0 +1,51 @@
+// +build go1.18
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zenazn/goji/web"
+)
+
+func TestRequestIDKeys(t *testing.T) {
+	t.Parallel()
+	var c web.C
+	h := RequestID(&c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r, _ := http.NewRequest("GET", "/", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	id, ok := RequestIDEnvKey.Get(c)
+	if !ok || !strings.HasPrefix(id, prefix) {
+		t.Errorf("Unexpected request ID %q", id)
+	}
+	if c.Env[RequestIDKey] != id || GetReqID(c) != id {
+		t.Errorf("Expected %q under the plain key too, got %v", id,
+			c.Env[RequestIDKey])
+	}
+
+	// Plain keys set by older code are still honored
+	c.Env[RequestIDKey] = "legacy"
+	if GetReqID(c) != "legacy" {
+		t.Errorf("Expected legacy, got %q", GetReqID(c))
+	}
+	delete(c.Env, RequestIDKey)
+	if id := GetReqID(c); id != "" {
+		t.Errorf("Expected deleted request ID to be absent, got %q", id)
+	}
+}
+
+func TestURLQueryKeys(t *testing.T) {
+	t.Parallel()
+	var c web.C
+	h := URLQuery(&c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r, _ := http.NewRequest("GET", "/?a=b", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if q, ok := URLQueryEnvKey.Get(c); !ok || q.Get("a") != "b" {
+		t.Errorf("Unexpected query %v", q)
+	}
+}
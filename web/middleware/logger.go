@@ -2,8 +2,12 @@ package middleware
 
 import (
 	"bytes"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/zenazn/goji/web"
@@ -20,73 +24,267 @@ import (
 // Logger has been designed explicitly to be Good Enough for use in small
 // applications and for people just getting started with Goji. It is expected
 // that applications will eventually outgrow this middleware and replace it with
-// a custom request logger, such as one that produces machine-parseable output,
-// outputs logs to a different service (e.g., syslog), or formats lines like
-// those printed elsewhere in the application.
+// a custom request logger, such as one that produces machine-parseable output
+// (see NewStructuredLogger), outputs logs to a different service (e.g.,
+// syslog), or formats lines like those printed elsewhere in the application.
+// Many of these customizations are available using NewLogger.
 func Logger(c *web.C, h http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		reqID := GetReqID(*c)
+	return defaultLogger(c, h)
+}
+
+var defaultLogger = NewLogger(LoggerOptions{})
+
+// AccessLogFormat is a format for the lines written by a Logger. It is distinct
+// from LogFormat, which is an encoding for the records written by a structured
+// logger (see NewStructuredLogger).
+type AccessLogFormat int
+
+const (
+	// AccessLogDefault prints a line when each request starts and another
+	// when it ends, as Logger does.
+	AccessLogDefault AccessLogFormat = iota
+	// AccessLogCommon prints a single line per request in the Apache Common
+	// Log Format.
+	AccessLogCommon
+	// AccessLogCombined prints a single line per request in the Apache
+	// Combined Log Format, which adds the Referer and User-Agent headers to
+	// the Common Log Format.
+	AccessLogCombined
+)
+
+// ColorMode controls whether a Logger prints in color.
+type ColorMode int
 
-		printStart(reqID, r)
+const (
+	// ColorAuto prints in color only when the output is a TTY: standard
+	// output if LoggerOptions.Output is nil, or Output itself if it is an
+	// *os.File. Other writers never receive color.
+	ColorAuto ColorMode = iota
+	// ColorAlways always prints in color.
+	ColorAlways
+	// ColorNever never prints in color.
+	ColorNever
+)
 
-		lw := mutil.WrapWriter(w)
+// LoggerOptions configures a Logger. See NewLogger.
+type LoggerOptions struct {
+	// Output is the writer lines are written to. Lines in the
+	// AccessLogDefault format are prefixed with the date and time, and lines
+	// in the Apache formats (which contain their own timestamps) are written
+	// as-is. If Output is nil, lines in the AccessLogDefault format are
+	// printed using the log package's standard logger, as Logger does, and
+	// lines in the Apache formats are written to os.Stderr.
+	Output io.Writer
+	// Color controls whether lines in the AccessLogDefault format are
+	// printed in color. Lines in the Apache formats are never printed in
+	// color.
+	Color ColorMode
+	// Format is the format lines are printed in. The default is
+	// AccessLogDefault.
+	Format AccessLogFormat
+	// SkipPaths is a list of request paths, such as "/healthz", for which
+	// nothing is logged.
+	SkipPaths []string
+	// SlowThreshold, if nonzero, causes only requests which took at least
+	// this long to be logged.
+	SlowThreshold time.Duration
+}
 
-		t1 := time.Now()
-		h.ServeHTTP(lw, r)
+type logger struct {
+	print  func(string)
+	color  ColorMode
+	format AccessLogFormat
+	skip   map[string]struct{}
+	slow   time.Duration
+}
 
-		if lw.Status() == 0 {
-			lw.WriteHeader(http.StatusOK)
+// NewLogger returns a request logging middleware configured using the given
+// options. NewLogger(LoggerOptions{}) is equivalent to Logger.
+func NewLogger(opts LoggerOptions) func(*web.C, http.Handler) http.Handler {
+	l := &logger{
+		print:  func(s string) { log.Print(s) },
+		color:  opts.Color,
+		format: opts.Format,
+		slow:   opts.SlowThreshold,
+	}
+	output := opts.Output
+	if output == nil && opts.Format != AccessLogDefault {
+		// The standard logger would add a second timestamp.
+		output = os.Stderr
+	}
+	if output != nil {
+		flags := 0
+		if opts.Format == AccessLogDefault {
+			flags = log.LstdFlags
 		}
-		t2 := time.Now()
+		out := log.New(output, "", flags)
+		l.print = func(s string) { out.Print(s) }
 
-		printEnd(reqID, lw, t2.Sub(t1))
+		// Whether standard output is a TTY says nothing about Output.
+		if l.color == ColorAuto {
+			l.color = ColorNever
+			if f, ok := output.(*os.File); ok && isTerminal(f) {
+				l.color = ColorAlways
+			}
+		}
+	}
+	if len(opts.SkipPaths) != 0 {
+		l.skip = make(map[string]struct{}, len(opts.SkipPaths))
+		for _, path := range opts.SkipPaths {
+			l.skip[path] = struct{}{}
+		}
 	}
 
-	return http.HandlerFunc(fn)
+	return func(c *web.C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := l.skip[r.URL.Path]; ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			l.serve(c, h, w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
 }
 
-func printStart(reqID string, r *http.Request) {
+func (l *logger) serve(c *web.C, h http.Handler, w http.ResponseWriter, r *http.Request) {
+	reqID := GetReqID(*c)
+
+	// Since we don't know how long the request will take, we have to save
+	// the start line for later if we're only logging slow requests.
+	var start string
+	if l.format == AccessLogDefault {
+		start = l.startLine(reqID, r)
+		if l.slow == 0 {
+			l.print(start)
+		}
+	}
+
+	lw := mutil.WrapWriter(w)
+
+	t1 := time.Now()
+	h.ServeHTTP(lw, r)
+
+	if lw.Status() == 0 {
+		lw.WriteHeader(http.StatusOK)
+	}
+	t2 := time.Now()
+
+	dt := t2.Sub(t1)
+	if dt < l.slow {
+		return
+	}
+	switch l.format {
+	case AccessLogCommon, AccessLogCombined:
+		l.print(l.apacheLine(r, lw, t1))
+	default:
+		if l.slow != 0 {
+			l.print(start)
+		}
+		l.print(l.endLine(reqID, lw, dt))
+	}
+}
+
+// useColor is evaluated lazily, since isTTY is not yet set when package-level
+// loggers (like the one backing Logger) are constructed.
+func (l *logger) useColor() bool {
+	switch l.color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return isTTY
+}
+
+func (l *logger) startLine(reqID string, r *http.Request) string {
+	color := l.useColor()
 	var buf bytes.Buffer
 
 	if reqID != "" {
-		cW(&buf, bBlack, "[%s] ", reqID)
+		colorWrite(&buf, color, bBlack, "[%s] ", reqID)
 	}
 	buf.WriteString("Started ")
-	cW(&buf, bMagenta, "%s ", r.Method)
-	cW(&buf, nBlue, "%q ", r.URL.String())
+	colorWrite(&buf, color, bMagenta, "%s ", r.Method)
+	colorWrite(&buf, color, nBlue, "%q ", r.URL.String())
 	buf.WriteString("from ")
 	buf.WriteString(r.RemoteAddr)
 
-	log.Print(buf.String())
+	return buf.String()
 }
 
-func printEnd(reqID string, w mutil.WriterProxy, dt time.Duration) {
+func (l *logger) endLine(reqID string, w mutil.WriterProxy, dt time.Duration) string {
+	color := l.useColor()
 	var buf bytes.Buffer
 
 	if reqID != "" {
-		cW(&buf, bBlack, "[%s] ", reqID)
+		colorWrite(&buf, color, bBlack, "[%s] ", reqID)
 	}
 	buf.WriteString("Returning ")
 	status := w.Status()
 	if status < 200 {
-		cW(&buf, bBlue, "%03d", status)
+		colorWrite(&buf, color, bBlue, "%03d", status)
 	} else if status < 300 {
-		cW(&buf, bGreen, "%03d", status)
+		colorWrite(&buf, color, bGreen, "%03d", status)
 	} else if status < 400 {
-		cW(&buf, bCyan, "%03d", status)
+		colorWrite(&buf, color, bCyan, "%03d", status)
 	} else if status < 500 {
-		cW(&buf, bYellow, "%03d", status)
+		colorWrite(&buf, color, bYellow, "%03d", status)
 	} else {
-		cW(&buf, bRed, "%03d", status)
+		colorWrite(&buf, color, bRed, "%03d", status)
 	}
 	buf.WriteString(" in ")
 	if dt < 500*time.Millisecond {
-		cW(&buf, nGreen, "%s", dt)
+		colorWrite(&buf, color, nGreen, "%s", dt)
 	} else if dt < 5*time.Second {
-		cW(&buf, nYellow, "%s", dt)
+		colorWrite(&buf, color, nYellow, "%s", dt)
+	} else {
+		colorWrite(&buf, color, nRed, "%s", dt)
+	}
+
+	return buf.String()
+}
+
+// apacheLine formats a line in the Apache Common or Combined Log Format.
+func (l *logger) apacheLine(r *http.Request, w mutil.WriterProxy, t time.Time) string {
+	var buf bytes.Buffer
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	buf.WriteString(apacheField(host))
+	buf.WriteString(" - ")
+	user, _, _ := r.BasicAuth()
+	buf.WriteString(apacheField(user))
+	buf.WriteString(" [")
+	buf.WriteString(t.Format("02/Jan/2006:15:04:05 -0700"))
+	buf.WriteString("] ")
+	buf.WriteString(strconv.Quote(r.Method + " " + r.RequestURI + " " +
+		r.Proto))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(w.Status()))
+	buf.WriteByte(' ')
+	if n := w.BytesWritten(); n != 0 {
+		buf.WriteString(strconv.Itoa(n))
 	} else {
-		cW(&buf, nRed, "%s", dt)
+		buf.WriteByte('-')
 	}
 
-	log.Print(buf.String())
+	if l.format == AccessLogCombined {
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.Quote(r.Referer()))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.Quote(r.UserAgent()))
+	}
+
+	return buf.String()
+}
+
+// apacheField returns "-" in place of empty fields.
+func apacheField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
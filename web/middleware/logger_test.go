@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zenazn/goji/web"
+)
+
+func loggerMux(opts LoggerOptions) *web.Mux {
+	m := web.New()
+	m.Use(NewLogger(opts))
+	m.Get("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	m.Get("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	m.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	})
+	return m
+}
+
+func loggerRequest(m *web.Mux, path string) {
+	r, _ := http.NewRequest("GET", path, nil)
+	r.RequestURI = path
+	r.RemoteAddr = "1.2.3.4:5678"
+	m.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestLoggerOutput(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := loggerMux(LoggerOptions{Output: &out, Color: ColorNever})
+	loggerRequest(m, "/hello")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two lines, got %q", out.String())
+	}
+	if !strings.HasSuffix(lines[0], `Started GET "/hello" from 1.2.3.4:5678`) {
+		t.Errorf("Unexpected start line %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "Returning 200 in ") {
+		t.Errorf("Unexpected end line %q", lines[1])
+	}
+	if strings.Contains(out.String(), "\033") {
+		t.Errorf("Expected no color codes in %q", out.String())
+	}
+}
+
+func TestLoggerColor(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := loggerMux(LoggerOptions{Output: &out, Color: ColorAlways})
+	loggerRequest(m, "/hello")
+
+	if !bytes.Contains(out.Bytes(), bMagenta) ||
+		!bytes.Contains(out.Bytes(), bGreen) {
+		t.Errorf("Expected color codes in %q", out.String())
+	}
+}
+
+func TestLoggerColorAutoOutput(t *testing.T) {
+	// Not parallel, since it modifies isTTY.
+	defer func(tty bool) { isTTY = tty }(isTTY)
+	isTTY = true
+
+	var out bytes.Buffer
+	m := loggerMux(LoggerOptions{Output: &out})
+	loggerRequest(m, "/hello")
+	if out.Len() == 0 || strings.Contains(out.String(), "\033") {
+		t.Errorf("Expected no color codes in %q", out.String())
+	}
+
+	f, err := ioutil.TempFile("", "goji-logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	m = loggerMux(LoggerOptions{Output: f})
+	loggerRequest(m, "/hello")
+	b, _ := ioutil.ReadFile(f.Name())
+	if len(b) == 0 || bytes.Contains(b, []byte("\033")) {
+		t.Errorf("Expected no color codes in %q", b)
+	}
+}
+
+var commonRe = regexp.MustCompile(`^1\.2\.3\.4 - (-|carl) ` +
+	`\[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [-+]\d{4}\] ` +
+	`"GET (/hello|/empty) HTTP/1\.1" (200 5|204 -)`)
+
+func TestLoggerCommon(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := loggerMux(LoggerOptions{Output: &out, Format: AccessLogCommon})
+	loggerRequest(m, "/hello")
+	loggerRequest(m, "/empty")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two lines, got %q", out.String())
+	}
+	for _, line := range lines {
+		if !commonRe.MatchString(line) || strings.Contains(line, `" "`) {
+			t.Errorf("Unexpected line %q", line)
+		}
+	}
+}
+
+func TestLoggerCommonStderr(t *testing.T) {
+	// Not parallel, since it modifies os.Stderr.
+	f, err := ioutil.TempFile("", "goji-logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	defer func(stderr *os.File) { os.Stderr = stderr }(os.Stderr)
+	os.Stderr = f
+
+	m := loggerMux(LoggerOptions{Format: AccessLogCommon})
+	loggerRequest(m, "/hello")
+
+	b, _ := ioutil.ReadFile(f.Name())
+	line := strings.TrimSuffix(string(b), "\n")
+	if !commonRe.MatchString(line) {
+		t.Errorf("Expected a bare Common Log Format line, got %q", line)
+	}
+}
+
+func TestLoggerCombined(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := loggerMux(LoggerOptions{Output: &out, Format: AccessLogCombined})
+
+	r, _ := http.NewRequest("GET", "/hello", nil)
+	r.RequestURI = "/hello"
+	r.RemoteAddr = "1.2.3.4:5678"
+	r.SetBasicAuth("carl", "hunter2")
+	r.Header.Set("Referer", "http://example.com/")
+	r.Header.Set("User-Agent", "test agent")
+	m.ServeHTTP(httptest.NewRecorder(), r)
+
+	line := strings.TrimSuffix(out.String(), "\n")
+	if !commonRe.MatchString(line) ||
+		!strings.HasPrefix(line, "1.2.3.4 - carl ") ||
+		!strings.HasSuffix(line, `200 5 "http://example.com/" "test agent"`) {
+		t.Errorf("Unexpected line %q", line)
+	}
+}
+
+func TestLoggerSkipPaths(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := loggerMux(LoggerOptions{
+		Output:    &out,
+		Format:    AccessLogCommon,
+		SkipPaths: []string{"/empty"},
+	})
+	loggerRequest(m, "/empty")
+	if out.Len() != 0 {
+		t.Errorf("Expected skipped path not to be logged, got %q",
+			out.String())
+	}
+	loggerRequest(m, "/hello")
+	if out.Len() == 0 {
+		t.Error("Expected path to be logged")
+	}
+}
+
+func TestLoggerSlowThreshold(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := loggerMux(LoggerOptions{
+		Output:        &out,
+		Color:         ColorNever,
+		SlowThreshold: 10 * time.Millisecond,
+	})
+	loggerRequest(m, "/hello")
+	if out.Len() != 0 {
+		t.Errorf("Expected fast request not to be logged, got %q",
+			out.String())
+	}
+	loggerRequest(m, "/slow")
+	if n := strings.Count(out.String(), "\n"); n != 2 ||
+		!strings.Contains(out.String(), `Started GET "/slow"`) {
+		t.Errorf("Expected slow request to be logged, got %q",
+			out.String())
+	}
+}
@@ -43,7 +43,8 @@ func (p *autoOptionsProxy) WriteHeader(code int) {
 	methods := getValidMethods(*p.c)
 	switch p.state {
 	case aosInit:
-		if methods != nil && code == http.StatusNotFound {
+		if methods != nil && (code == http.StatusNotFound ||
+			code == http.StatusMethodNotAllowed) {
 			p.state = aosProxying
 			break
 		}
@@ -60,7 +61,8 @@ func (p *autoOptionsProxy) WriteHeader(code int) {
 }
 
 // AutomaticOptions automatically return an appropriate "Allow" header when the
-// request method is OPTIONS and the request would have otherwise been 404'd.
+// request method is OPTIONS and the request would have otherwise been 404'd or
+// 405'd.
 func AutomaticOptions(c *web.C, h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
@@ -73,20 +75,6 @@ func AutomaticOptions(c *web.C, h http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
-func getValidMethods(c web.C) []string {
-	if c.Env == nil {
-		return nil
-	}
-	v, ok := c.Env[web.ValidMethodsKey]
-	if !ok {
-		return nil
-	}
-	if methods, ok := v.([]string); ok {
-		return methods
-	}
-	return nil
-}
-
 func addMethod(methods []string, method string) []string {
 	for _, m := range methods {
 		if m == method {
@@ -90,6 +90,27 @@ func TestAutomaticOptions(t *testing.T) {
 			allow)
 	}
 
+	// The same goes for 405s
+	r, _ = http.NewRequest("OPTIONS", "/", nil)
+	rr = testOptions(r,
+		func(c *web.C, w http.ResponseWriter, r *http.Request) {
+			c.Env = optionsTestEnv
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte{'h', 'i'})
+		},
+	)
+	if rr.Code != http.StatusOK {
+		t.Errorf("status is %d, not 200", rr.Code)
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("body was %q, should be empty", rr.Body.String())
+	}
+	allow = rr.HeaderMap.Get("Allow")
+	if allow != correctHeaders {
+		t.Errorf("Allow header should be %q, was %q", correctHeaders,
+			allow)
+	}
+
 	// If we somehow 404 without giving a list of valid options, don't do
 	// anything
 	r, _ = http.NewRequest("OPTIONS", "/", nil)
@@ -110,3 +131,44 @@ func TestAutomaticOptions(t *testing.T) {
 		t.Errorf("Allow header was set to %q, should be empty", allow)
 	}
 }
+
+func TestAutomaticOptionsMux(t *testing.T) {
+	t.Parallel()
+
+	m := web.New()
+	m.Use(AutomaticOptions)
+	m.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	m.Put("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	r, _ := http.NewRequest("OPTIONS", "/", nil)
+	rr := httptest.NewRecorder()
+	m.ServeHTTP(rr, r)
+	if rr.Code != http.StatusOK {
+		t.Errorf("status is %d, not 200", rr.Code)
+	}
+	allow := rr.HeaderMap.Get("Allow")
+	correctHeaders := "GET, HEAD, PUT, OPTIONS"
+	if allow != correctHeaders {
+		t.Errorf("Allow header should be %q, was %q", correctHeaders,
+			allow)
+	}
+}
+
+func TestAutomaticOptionsExtensionMethods(t *testing.T) {
+	t.Parallel()
+
+	m := web.New()
+	m.Use(AutomaticOptions)
+	m.Method("PROPFIND", "/", func(w http.ResponseWriter, r *http.Request) {})
+	m.Method("MKCOL", "/", func(w http.ResponseWriter, r *http.Request) {})
+
+	r, _ := http.NewRequest("OPTIONS", "/", nil)
+	rr := httptest.NewRecorder()
+	m.ServeHTTP(rr, r)
+	allow := rr.HeaderMap.Get("Allow")
+	correctHeaders := "MKCOL, PROPFIND, OPTIONS"
+	if allow != correctHeaders {
+		t.Errorf("Allow header should be %q, was %q", correctHeaders,
+			allow)
+	}
+}
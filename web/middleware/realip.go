@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"log"
+	"net"
 	"net/http"
 	"strings"
 )
 
 var xForwardedFor = http.CanonicalHeaderKey("X-Forwarded-For")
 var xRealIP = http.CanonicalHeaderKey("X-Real-IP")
+var forwarded = http.CanonicalHeaderKey("Forwarded")
+var xForwardedProto = http.CanonicalHeaderKey("X-Forwarded-Proto")
+var xForwardedHost = http.CanonicalHeaderKey("X-Forwarded-Host")
 
 // RealIP is a middleware that sets a http.Request's RemoteAddr to the results
 // of parsing either the X-Forwarded-For header or the X-Real-IP header (in that
@@ -22,7 +27,9 @@ var xRealIP = http.CanonicalHeaderKey("X-Real-IP")
 // Goji. If your reverse proxies are configured to pass along arbitrary header
 // values from the client, or if you use this middleware without a reverse
 // proxy, malicious clients will be able to make you very sad (or, depending on
-// how you're using RemoteAddr, vulnerable to an attack of some sort).
+// how you're using RemoteAddr, vulnerable to an attack of some sort). NewRealIP
+// returns a version of this middleware which only trusts headers passed by
+// known proxies.
 func RealIP(h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		if rip := realIP(r); rip != "" {
@@ -49,3 +56,275 @@ func realIP(r *http.Request) string {
 
 	return ip
 }
+
+// ProxyHeader names the header a trust-aware RealIP takes the client's address
+// from. See RealIPOptions.
+type ProxyHeader int
+
+const (
+	// ProxyXForwardedFor uses the X-Forwarded-For header, along with
+	// X-Forwarded-Proto and X-Forwarded-Host. This is the default.
+	ProxyXForwardedFor ProxyHeader = iota
+	// ProxyForwarded uses the RFC 7239 Forwarded header.
+	ProxyForwarded
+	// ProxyXRealIP uses the X-Real-IP header, along with X-Forwarded-Proto
+	// and X-Forwarded-Host.
+	ProxyXRealIP
+)
+
+// RealIPOptions configures a trust-aware RealIP. See NewRealIP.
+type RealIPOptions struct {
+	// TrustedProxies is a list of the addresses of proxies whose forwarding
+	// headers should be honored, either as CIDR ranges like "10.0.0.0/8"
+	// or as individual IP addresses.
+	TrustedProxies []string
+	// Header is the forwarding header the trusted proxies maintain. Every
+	// other forwarding header is ignored, since a proxy which does not
+	// maintain a header typically passes along whatever value the client
+	// sent.
+	Header ProxyHeader
+	// RewriteScheme causes the request's URL.Scheme to be set from the
+	// Forwarded header's "proto" parameter (with ProxyForwarded) or from
+	// X-Forwarded-Proto (otherwise).
+	RewriteScheme bool
+	// RewriteHost causes the request's Host to be set from the Forwarded
+	// header's "host" parameter (with ProxyForwarded) or from
+	// X-Forwarded-Host (otherwise).
+	RewriteHost bool
+}
+
+// NewRealIP returns a middleware that, like RealIP, sets a http.Request's
+// RemoteAddr to the address of the client which originated the request. Unlike
+// RealIP, forwarding headers are only honored if the request was received
+// directly from one of the proxies in opts.TrustedProxies, so it is safe to use
+// even if clients can reach Goji without going through a proxy.
+//
+// The client's address is taken only from the header named by opts.Header,
+// which must be one the trusted proxies set or append to themselves: a client
+// could otherwise supply any address it liked in a header the proxies pass
+// through untouched. For X-Forwarded-For and the RFC 7239 Forwarded header, the
+// list of addresses is walked from the right (i.e., starting with the address
+// added by the nearest proxy), skipping the addresses of trusted proxies. The
+// first untrusted address is the client's. If every address is trusted, the
+// leftmost one is used, and if an address cannot be parsed, RemoteAddr is left
+// unchanged.
+//
+// With ProxyForwarded, the scheme and host (if requested) are taken from the
+// same element of the Forwarded header as the client's address. Otherwise, the
+// rightmost values of X-Forwarded-Proto and X-Forwarded-Host are used.
+//
+// NewRealIP fatally exits (using log.Fatalf) if one of opts.TrustedProxies is
+// neither a valid CIDR range nor a valid IP address.
+func NewRealIP(opts RealIPOptions) func(http.Handler) http.Handler {
+	trusted := make([]*net.IPNet, len(opts.TrustedProxies))
+	for i, s := range opts.TrustedProxies {
+		trusted[i] = parseTrusted(s)
+	}
+	rip := &realIPResolver{trusted: trusted, opts: opts}
+
+	return func(h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			rip.rewrite(r)
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func parseTrusted(s string) *net.IPNet {
+	if _, n, err := net.ParseCIDR(s); err == nil {
+		return n
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		log.Fatalf("middleware: invalid trusted proxy %q", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+type realIPResolver struct {
+	trusted []*net.IPNet
+	opts    RealIPOptions
+}
+
+func (rip *realIPResolver) isTrusted(ip net.IP) bool {
+	for _, n := range rip.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedElement is a single hop described by a forwarding header.
+type forwardedElement struct {
+	addr, proto, host string
+}
+
+func (rip *realIPResolver) rewrite(r *http.Request) {
+	peer := parseNodeIP(r.RemoteAddr)
+	if peer == nil || !rip.isTrusted(peer) {
+		return
+	}
+
+	var hops []forwardedElement
+	switch rip.opts.Header {
+	case ProxyForwarded:
+		hops = parseForwarded(r.Header[forwarded])
+	case ProxyXForwardedFor:
+		for _, addr := range splitList(r.Header[xForwardedFor]) {
+			hops = append(hops, forwardedElement{addr: addr})
+		}
+	case ProxyXRealIP:
+		if xrip := r.Header.Get(xRealIP); xrip != "" {
+			hops = []forwardedElement{{addr: xrip}}
+		}
+	}
+	if len(hops) != 0 && rip.opts.Header != ProxyForwarded {
+		last := &hops[len(hops)-1]
+		last.proto = lastListValue(r.Header[xForwardedProto])
+		last.host = lastListValue(r.Header[xForwardedHost])
+	}
+	if len(hops) == 0 {
+		return
+	}
+
+	i := len(hops) - 1
+	var ip net.IP
+	for ; i >= 0; i-- {
+		ip = parseNodeIP(hops[i].addr)
+		if ip == nil {
+			return
+		}
+		if !rip.isTrusted(ip) || i == 0 {
+			break
+		}
+	}
+	hop := hops[i]
+
+	r.RemoteAddr = ip.String()
+	if rip.opts.RewriteScheme {
+		// X-Forwarded-Proto describes the most recent hop only.
+		if hop.proto == "" {
+			hop.proto = hops[len(hops)-1].proto
+		}
+		if p := strings.ToLower(hop.proto); p == "http" || p == "https" {
+			r.URL.Scheme = p
+		}
+	}
+	if rip.opts.RewriteHost {
+		if hop.host == "" {
+			hop.host = hops[len(hops)-1].host
+		}
+		if hop.host != "" {
+			r.Host = hop.host
+		}
+	}
+}
+
+// parseNodeIP parses an address as it may appear in RemoteAddr or a forwarding
+// header: an IP address, optionally with a port, and optionally with IPv6
+// addresses enclosed in brackets. It returns nil if the address cannot be
+// parsed.
+func parseNodeIP(s string) net.IP {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	return net.ParseIP(s)
+}
+
+// parseForwarded parses the elements of the RFC 7239 Forwarded header, which
+// may span several header lines.
+func parseForwarded(lines []string) []forwardedElement {
+	var elems []forwardedElement
+	for _, line := range lines {
+		for _, raw := range splitQuoted(line, ',') {
+			var elem forwardedElement
+			for _, pair := range splitQuoted(raw, ';') {
+				i := strings.IndexByte(pair, '=')
+				if i == -1 {
+					continue
+				}
+				k := strings.ToLower(strings.TrimSpace(pair[:i]))
+				v := unquote(strings.TrimSpace(pair[i+1:]))
+				switch k {
+				case "for":
+					elem.addr = v
+				case "proto":
+					elem.proto = v
+				case "host":
+					elem.host = v
+				}
+			}
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// splitQuoted splits s on sep, ignoring separators inside quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	if strings.IndexByte(s, '\\') == -1 {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+// splitList returns the comma-separated values of a list-valued header, which
+// may span several header lines.
+func splitList(lines []string) []string {
+	var values []string
+	for _, line := range lines {
+		for _, v := range strings.Split(line, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func lastListValue(lines []string) string {
+	values := splitList(lines)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type realIPResult struct {
+	addr, scheme, host string
+}
+
+func realIPRequest(opts RealIPOptions, remote string, headers map[string][]string) realIPResult {
+	var res realIPResult
+	h := NewRealIP(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res = realIPResult{r.RemoteAddr, r.URL.Scheme, r.Host}
+	}))
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Host = "internal"
+	r.RemoteAddr = remote
+	for k, v := range headers {
+		r.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	return res
+}
+
+var realIPTests = []struct {
+	header  ProxyHeader
+	remote  string
+	headers map[string][]string
+	addr    string
+}{
+	// Untrusted peers can't spoof their address
+	{ProxyXForwardedFor, "1.2.3.4:5678", map[string][]string{
+		"X-Forwarded-For": {"6.6.6.6"},
+	}, "1.2.3.4:5678"},
+	{ProxyXRealIP, "1.2.3.4:5678", map[string][]string{
+		"X-Real-IP": {"6.6.6.6"},
+	}, "1.2.3.4:5678"},
+	// No forwarding headers
+	{ProxyXForwardedFor, "10.0.0.1:5678", nil, "10.0.0.1:5678"},
+	{ProxyForwarded, "10.0.0.1:5678", nil, "10.0.0.1:5678"},
+	// Trusted peers
+	{ProxyXForwardedFor, "10.0.0.1:5678", map[string][]string{
+		"X-Forwarded-For": {"1.2.3.4"},
+	}, "1.2.3.4"},
+	{ProxyXRealIP, "10.0.0.1:5678", map[string][]string{
+		"X-Real-IP": {"1.2.3.4"},
+	}, "1.2.3.4"},
+	// Walking from the right, skipping trusted hops
+	{ProxyXForwardedFor, "10.0.0.1:5678", map[string][]string{
+		"X-Forwarded-For": {"6.6.6.6, 1.2.3.4, 10.1.2.3", "192.168.1.1"},
+	}, "1.2.3.4"},
+	{ProxyXForwardedFor, "10.0.0.1:5678", map[string][]string{
+		"X-Forwarded-For": {"10.2.2.2, 10.1.1.1"},
+	}, "10.2.2.2"},
+	{ProxyXForwardedFor, "10.0.0.1:5678", map[string][]string{
+		"X-Forwarded-For": {"6.6.6.6, garbage, 10.1.1.1"},
+	}, "10.0.0.1:5678"},
+	// Headers the proxies don't maintain are ignored
+	{ProxyXForwardedFor, "10.0.0.1:5678", map[string][]string{
+		"Forwarded":       {"for=1.1.1.1"},
+		"X-Forwarded-For": {"6.6.6.6"},
+	}, "6.6.6.6"},
+	{ProxyXForwardedFor, "10.0.0.1:5678", map[string][]string{
+		"Forwarded": {"for=1.1.1.1"},
+		"X-Real-IP": {"1.1.1.1"},
+	}, "10.0.0.1:5678"},
+	{ProxyXRealIP, "10.0.0.1:5678", map[string][]string{
+		"X-Forwarded-For": {"1.1.1.1"},
+	}, "10.0.0.1:5678"},
+	// RFC 7239
+	{ProxyForwarded, "10.0.0.1:5678", map[string][]string{
+		"Forwarded":       {`for=6.6.6.6, for="1.2.3.4:80";proto=https`},
+		"X-Forwarded-For": {"5.5.5.5"},
+	}, "1.2.3.4"},
+	{ProxyForwarded, "[::1]:5678", map[string][]string{
+		"Forwarded": {`For="[2001:db8:cafe::17]:4711"`, "for=10.0.0.2"},
+	}, "2001:db8:cafe::17"},
+}
+
+func TestNewRealIP(t *testing.T) {
+	t.Parallel()
+	opts := RealIPOptions{
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1", "::1"},
+	}
+	for i, test := range realIPTests {
+		opts.Header = test.header
+		res := realIPRequest(opts, test.remote, test.headers)
+		if res.addr != test.addr {
+			t.Errorf("%d: expected RemoteAddr %q, got %q", i,
+				test.addr, res.addr)
+		}
+		if res.scheme != "" || res.host != "internal" {
+			t.Errorf("%d: unexpected rewrite %+v", i, res)
+		}
+	}
+}
+
+func TestNewRealIPRewrite(t *testing.T) {
+	t.Parallel()
+	opts := RealIPOptions{
+		TrustedProxies: []string{"10.0.0.0/8"},
+		RewriteScheme:  true,
+		RewriteHost:    true,
+	}
+
+	res := realIPRequest(opts, "10.0.0.1:5678", map[string][]string{
+		"X-Forwarded-For":   {"1.2.3.4"},
+		"X-Forwarded-Proto": {"https"},
+		"X-Forwarded-Host":  {"example.com"},
+	})
+	if res != (realIPResult{"1.2.3.4", "https", "example.com"}) {
+		t.Errorf("Unexpected result %+v", res)
+	}
+
+	opts.Header = ProxyForwarded
+	res = realIPRequest(opts, "10.0.0.1:5678", map[string][]string{
+		"Forwarded": {`for=1.2.3.4;proto=https;host="example.com", ` +
+			`for=10.0.0.2;proto=http;host=internal2`},
+	})
+	if res != (realIPResult{"1.2.3.4", "https", "example.com"}) {
+		t.Errorf("Unexpected result %+v", res)
+	}
+
+	opts.Header = ProxyXForwardedFor
+	res = realIPRequest(opts, "1.2.3.4:5678", map[string][]string{
+		"X-Forwarded-Proto": {"https"},
+		"X-Forwarded-Host":  {"evil.com"},
+	})
+	if res != (realIPResult{"1.2.3.4:5678", "", "internal"}) {
+		t.Errorf("Expected untrusted peer not to rewrite, got %+v", res)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"log"
 	"net/http"
+	"os"
 	"runtime/debug"
 
 	"github.com/zenazn/goji/web"
+	"github.com/zenazn/goji/web/mutil"
 )
 
 // Recoverer is a middleware that recovers from panics, logs the panic (and a
@@ -14,22 +16,78 @@ import (
 // possible.
 //
 // Recoverer prints a request ID if one is provided.
+//
+// To report panics elsewhere or to customize the error response, see
+// NewRecoverer.
 func Recoverer(c *web.C, h http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		reqID := GetReqID(*c)
-
-		defer func() {
-			if err := recover(); err != nil {
-				printPanic(reqID, err)
-				debug.PrintStack()
-				http.Error(w, http.StatusText(500), 500)
-			}
-		}()
-
-		h.ServeHTTP(w, r)
+	return defaultRecoverer(c, h)
+}
+
+var defaultRecoverer = NewRecoverer(RecovererOptions{})
+
+// RecovererOptions configures a Recoverer. See NewRecoverer.
+type RecovererOptions struct {
+	// OnPanic is called with the recovered value and a backtrace of the
+	// panicking goroutine each time a request panics, for instance to
+	// report the panic to an error tracker. If it is nil, the panic and
+	// backtrace are logged, as Recoverer does.
+	OnPanic func(c *web.C, r *http.Request, err interface{}, stack []byte)
+	// ErrorHandler is called to respond to a request which panicked. It is
+	// only called if a response status has not already been written. If it
+	// is nil, a plain-text 500 (Internal Server Error) is returned.
+	ErrorHandler func(c *web.C, w http.ResponseWriter, r *http.Request, err interface{})
+}
+
+// NewRecoverer returns a middleware that recovers from panics, reports them
+// using opts.OnPanic, and responds using opts.ErrorHandler.
+// NewRecoverer(RecovererOptions{}) is equivalent to Recoverer.
+//
+// Panics with the value http.ErrAbortHandler, which are used to abort a
+// response, are not recovered.
+func NewRecoverer(opts RecovererOptions) func(*web.C, http.Handler) http.Handler {
+	onPanic := opts.OnPanic
+	if onPanic == nil {
+		onPanic = logPanic
+	}
+	errorHandler := opts.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = internalError
 	}
 
-	return http.HandlerFunc(fn)
+	return func(c *web.C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			lw := mutil.WrapWriter(w)
+
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if isAbort(err) {
+					panic(err)
+				}
+				onPanic(c, r, err, debug.Stack())
+				// If we've already started writing the response,
+				// there's no way to take it back.
+				if lw.Status() == 0 {
+					errorHandler(c, lw, r, err)
+				}
+			}()
+
+			h.ServeHTTP(lw, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func logPanic(c *web.C, r *http.Request, err interface{}, stack []byte) {
+	printPanic(GetReqID(*c), err)
+	os.Stderr.Write(stack)
+}
+
+func internalError(c *web.C, w http.ResponseWriter, r *http.Request, err interface{}) {
+	http.Error(w, http.StatusText(500), 500)
 }
 
 func printPanic(reqID string, err interface{}) {
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zenazn/goji/web"
+)
+
+// fakeTracker records panics as an error tracker would.
+type fakeTracker struct {
+	errs   []interface{}
+	stacks [][]byte
+	paths  []string
+	reqIDs []string
+}
+
+func (f *fakeTracker) report(c *web.C, r *http.Request, err interface{}, stack []byte) {
+	f.errs = append(f.errs, err)
+	f.stacks = append(f.stacks, stack)
+	f.paths = append(f.paths, r.URL.Path)
+	f.reqIDs = append(f.reqIDs, GetReqID(*c))
+}
+
+func recovererMux(opts RecovererOptions) *web.Mux {
+	m := web.New()
+	m.Use(RequestID)
+	m.Use(NewRecoverer(opts))
+	m.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("oh no")
+	})
+	m.Get("/partial", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("partial"))
+		panic("too late")
+	})
+	return m
+}
+
+func TestRecovererOnPanic(t *testing.T) {
+	t.Parallel()
+	var tracker fakeTracker
+	m := recovererMux(RecovererOptions{OnPanic: tracker.report})
+
+	r, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+	if len(tracker.errs) != 1 || tracker.errs[0] != "oh no" {
+		t.Fatalf("Expected panic to be reported, got %v", tracker.errs)
+	}
+	if !bytes.Contains(tracker.stacks[0], []byte("recoverer_test.go")) {
+		t.Errorf("Expected stack to include panicking frame, got %s",
+			tracker.stacks[0])
+	}
+	if tracker.paths[0] != "/panic" {
+		t.Errorf("Expected request path /panic, got %q", tracker.paths[0])
+	}
+	if !strings.HasPrefix(tracker.reqIDs[0], prefix) {
+		t.Errorf("Expected request ID, got %q", tracker.reqIDs[0])
+	}
+}
+
+func TestRecovererErrorHandler(t *testing.T) {
+	t.Parallel()
+	var tracker fakeTracker
+	m := recovererMux(RecovererOptions{
+		OnPanic: tracker.report,
+		ErrorHandler: func(c *web.C, w http.ResponseWriter, r *http.Request, err interface{}) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"internal"}`))
+		},
+	})
+
+	r, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError ||
+		w.Header().Get("Content-Type") != "application/json" ||
+		w.Body.String() != `{"error":"internal"}` {
+		t.Errorf("Unexpected response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRecovererHeadersWritten(t *testing.T) {
+	t.Parallel()
+	var tracker fakeTracker
+	called := false
+	m := recovererMux(RecovererOptions{
+		OnPanic: tracker.report,
+		ErrorHandler: func(c *web.C, w http.ResponseWriter, r *http.Request, err interface{}) {
+			called = true
+		},
+	})
+
+	r, _ := http.NewRequest("GET", "/partial", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+
+	if called {
+		t.Error("Expected error handler not to be called")
+	}
+	if w.Code != http.StatusAccepted || w.Body.String() != "partial" {
+		t.Errorf("Unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if len(tracker.errs) != 1 || tracker.errs[0] != "too late" {
+		t.Errorf("Expected panic to be reported, got %v", tracker.errs)
+	}
+}
@@ -3,11 +3,13 @@ package middleware
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/zenazn/goji/web"
 )
@@ -57,32 +59,157 @@ func init() {
 // where "random" is a base62 random string that uniquely identifies this go
 // process, and where the last number is an atomically incremented request
 // counter.
+//
+// To accept request IDs from upstream services, to return them to clients, or
+// to generate IDs in other formats, see NewRequestID.
 func RequestID(c *web.C, h http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		if c.Env == nil {
-			c.Env = make(map[interface{}]interface{})
+	return defaultRequestID(c, h)
+}
+
+var defaultRequestID = NewRequestID(RequestIDOptions{})
+
+// defaultMaxRequestIDLength is the default for RequestIDOptions.MaxLength.
+const defaultMaxRequestIDLength = 128
+
+// RequestIDOptions configures a RequestID middleware. See NewRequestID.
+type RequestIDOptions struct {
+	// Header is the name of a request header, such as "X-Request-Id", from
+	// which the request ID is taken if it is present and valid. A valid ID
+	// is at most MaxLength bytes long and consists only of ASCII letters,
+	// digits, and the characters "-_.:/+=@". If Header is empty, incoming
+	// IDs are ignored.
+	Header string
+	// MaxLength is the length of the longest incoming request ID which will
+	// be accepted. If it is zero, 128 is used.
+	MaxLength int
+	// ResponseHeader is the name of a response header, such as
+	// "X-Request-Id", to which the request ID is written. If it is empty,
+	// the request ID is not returned to the client.
+	ResponseHeader string
+	// Generator generates a request ID for requests which do not provide a
+	// valid one of their own. If it is nil, SequentialRequestID is used.
+	Generator func() string
+}
+
+// NewRequestID returns a middleware that, like RequestID, injects a request ID
+// into the context of each request, configured using the given options.
+// NewRequestID(RequestIDOptions{}) is equivalent to RequestID.
+func NewRequestID(opts RequestIDOptions) func(*web.C, http.Handler) http.Handler {
+	generate := opts.Generator
+	if generate == nil {
+		generate = SequentialRequestID
+	}
+	maxLength := opts.MaxLength
+	if maxLength == 0 {
+		maxLength = defaultMaxRequestIDLength
+	}
+
+	return func(c *web.C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var id string
+			if opts.Header != "" {
+				id = r.Header.Get(opts.Header)
+				if !validRequestID(id, maxLength) {
+					id = ""
+				}
+			}
+			if id == "" {
+				id = generate()
+			}
+			setReqID(c, id)
+			if opts.ResponseHeader != "" {
+				w.Header().Set(opts.ResponseHeader, id)
+			}
+
+			h.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func validRequestID(id string, maxLength int) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		b := id[i]
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' ||
+			'0' <= b && b <= '9' {
+			continue
 		}
-		myid := atomic.AddUint64(&reqid, 1)
-		c.Env[RequestIDKey] = fmt.Sprintf("%s-%06d", prefix, myid)
+		if strings.IndexByte("-_.:/+=@", b) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// SequentialRequestID generates request IDs of the form used by RequestID.
+func SequentialRequestID() string {
+	myid := atomic.AddUint64(&reqid, 1)
+	return fmt.Sprintf("%s-%06d", prefix, myid)
+}
+
+// UUIDRequestID generates request IDs which are random (version 4) UUIDs, like
+// "0b7c8e4a-3f1d-4e8a-9c2b-5d6e7f8a9b0c".
+func UUIDRequestID() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
 
-		h.ServeHTTP(w, r)
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDRequestID generates request IDs which are ULIDs: a 48-bit millisecond
+// timestamp followed by 80 random bits, encoded as 26 characters of Crockford's
+// base32, like "01ARZ3NDEKTSV4RRFFQ69G5FAV". ULIDs generated in different
+// milliseconds sort in the order they were generated.
+func ULIDRequestID() string {
+	return newULID(time.Now())
+}
+
+func newULID(t time.Time) string {
+	var u [16]byte
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	for i := 0; i < 6; i++ {
+		u[i] = byte(ms >> uint(40-8*i))
 	}
+	rand.Read(u[6:])
 
-	return http.HandlerFunc(fn)
+	// 26 characters of 5 bits each encode 130 bits, so the 128-bit ULID is
+	// padded with two leading zero bits.
+	var buf [26]byte
+	for i := range buf {
+		var v byte
+		for j := 0; j < 5; j++ {
+			bit := i*5 + j - 2
+			v <<= 1
+			if bit >= 0 && u[bit/8]&(0x80>>uint(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		buf[i] = crockford[v]
+	}
+	return string(buf[:])
 }
 
 // GetReqID returns a request ID from the given context if one is present.
 // Returns the empty string if a request ID cannot be found.
 func GetReqID(c web.C) string {
-	if c.Env == nil {
-		return ""
-	}
-	v, ok := c.Env[RequestIDKey]
-	if !ok {
-		return ""
-	}
-	if reqID, ok := v.(string); ok {
-		return reqID
-	}
-	return ""
+	id, _ := getReqID(c)
+	return id
 }
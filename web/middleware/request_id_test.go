@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zenazn/goji/web"
+)
+
+func requestIDMux(opts RequestIDOptions, id *string) *web.Mux {
+	m := web.New()
+	m.Use(NewRequestID(opts))
+	m.Get("/", func(c web.C, w http.ResponseWriter, r *http.Request) {
+		*id = GetReqID(c)
+	})
+	return m
+}
+
+func TestRequestIDDefault(t *testing.T) {
+	t.Parallel()
+	var id string
+	m := requestIDMux(RequestIDOptions{}, &id)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-Id", "upstream")
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+
+	if !strings.HasPrefix(id, prefix+"-") {
+		t.Errorf("Expected sequential request ID, got %q", id)
+	}
+	if h := w.Header().Get("X-Request-Id"); h != "" {
+		t.Errorf("Expected no response header, got %q", h)
+	}
+}
+
+var incomingIDTests = []struct {
+	incoming string
+	accepted bool
+}{
+	{"abc-123", true},
+	{"lb.example.com/Root=1-5759e988-bd862e3fe1be46a994272793", true},
+	{"0b7c8e4a-3f1d-4e8a-9c2b-5d6e7f8a9b0c", true},
+	{"", false},
+	{"has space", false},
+	{"<script>", false},
+	{"line\nbreak", false},
+	{strings.Repeat("a", 64), true},
+	{strings.Repeat("a", 65), false},
+}
+
+func TestRequestIDIncoming(t *testing.T) {
+	t.Parallel()
+	var id string
+	m := requestIDMux(RequestIDOptions{
+		Header:         "X-Request-Id",
+		MaxLength:      64,
+		ResponseHeader: "X-Request-Id",
+		Generator:      func() string { return "generated" },
+	}, &id)
+
+	for _, test := range incomingIDTests {
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header["X-Request-Id"] = []string{test.incoming}
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+
+		expected := "generated"
+		if test.accepted {
+			expected = test.incoming
+		}
+		if id != expected {
+			t.Errorf("For %q, expected request ID %q, got %q",
+				test.incoming, expected, id)
+		}
+		if h := w.Header().Get("X-Request-Id"); h != expected {
+			t.Errorf("For %q, expected response header %q, got %q",
+				test.incoming, expected, h)
+		}
+	}
+}
+
+var uuidRe = regexp.MustCompile(
+	`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestUUIDRequestID(t *testing.T) {
+	t.Parallel()
+	a, b := UUIDRequestID(), UUIDRequestID()
+	if !uuidRe.MatchString(a) || !uuidRe.MatchString(b) || a == b {
+		t.Errorf("Unexpected UUIDs %q and %q", a, b)
+	}
+}
+
+var ulidRe = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+
+func TestULIDRequestID(t *testing.T) {
+	t.Parallel()
+	a, b := ULIDRequestID(), ULIDRequestID()
+	if !ulidRe.MatchString(a) || !ulidRe.MatchString(b) || a == b {
+		t.Errorf("Unexpected ULIDs %q and %q", a, b)
+	}
+}
+
+// ulidTime decodes the millisecond timestamp in the first 10 characters of a
+// ULID.
+func ulidTime(id string) int64 {
+	var ms int64
+	for i := 0; i < 10; i++ {
+		ms = ms<<5 | int64(strings.IndexByte(crockford, id[i]))
+	}
+	return ms
+}
+
+func TestULIDOrder(t *testing.T) {
+	t.Parallel()
+	t1 := time.Date(2016, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	t2 := t1.Add(time.Millisecond)
+	a, b := newULID(t1), newULID(t2)
+
+	ms := t1.UnixNano() / int64(time.Millisecond)
+	if at := ulidTime(a); at != ms {
+		t.Errorf("Expected %q to encode %d, got %d", a, ms, at)
+	}
+	if bt := ulidTime(b); bt != ms+1 {
+		t.Errorf("Expected %q to encode %d, got %d", b, ms+1, bt)
+	}
+	if a >= b {
+		t.Errorf("Expected %q to sort before %q", a, b)
+	}
+}
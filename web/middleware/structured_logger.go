@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zenazn/goji/web"
+	"github.com/zenazn/goji/web/mutil"
+)
+
+// LogFormat is an encoding for the records written by a structured logger.
+type LogFormat int
+
+const (
+	// LogJSON encodes each record as a JSON object.
+	LogJSON LogFormat = iota
+	// LogLogfmt encodes each record as a line of logfmt-style key=value
+	// pairs.
+	LogLogfmt
+)
+
+// LogRecord describes a single request, as logged by a structured logger.
+type LogRecord struct {
+	// Time is the time at which the request began.
+	Time time.Time `json:"time"`
+	// RequestID is the request's ID (see RequestID), if it has one.
+	RequestID string `json:"request_id"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	// Pattern is the pattern of the route the request matched. It is only
+	// available if routing took place before the logger returned (see
+	// web.Mux.Router), and is empty otherwise.
+	Pattern    string  `json:"pattern"`
+	Status     int     `json:"status"`
+	Bytes      int     `json:"bytes"`
+	LatencyMS  float64 `json:"latency_ms"`
+	RemoteAddr string  `json:"remote_addr"`
+	UserAgent  string  `json:"user_agent"`
+}
+
+// StructuredLoggerOptions configures a structured logger. See
+// NewStructuredLogger.
+type StructuredLoggerOptions struct {
+	// Format is the encoding used for each record. The default is LogJSON.
+	Format LogFormat
+	// Output is the sink each encoded record is written to, followed by a
+	// newline. Each record is written using a single call to Write, and
+	// calls are never made concurrently. The default is os.Stderr.
+	Output io.Writer
+}
+
+type structuredLogger struct {
+	format LogFormat
+	mu     sync.Mutex
+	out    io.Writer
+}
+
+// NewStructuredLogger returns a middleware that writes a machine-readable record
+// (see LogRecord) describing each request once it has been handled. Unlike
+// Logger, it never prints in color, and writes a single record per request.
+//
+// The matched pattern is only recorded if the Mux's Router middleware is placed
+// before the structured logger in the middleware stack. The request ID is
+// recorded if the RequestID middleware is placed before it.
+func NewStructuredLogger(opts StructuredLoggerOptions) func(*web.C, http.Handler) http.Handler {
+	sl := &structuredLogger{format: opts.Format, out: opts.Output}
+	if sl.out == nil {
+		sl.out = os.Stderr
+	}
+
+	return func(c *web.C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			lw := mutil.WrapWriter(w)
+
+			t1 := time.Now()
+			h.ServeHTTP(lw, r)
+			if lw.Status() == 0 {
+				lw.WriteHeader(http.StatusOK)
+			}
+			t2 := time.Now()
+
+			record := LogRecord{
+				Time:       t1,
+				RequestID:  GetReqID(*c),
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				Status:     lw.Status(),
+				Bytes:      lw.BytesWritten(),
+				LatencyMS:  float64(t2.Sub(t1)) / float64(time.Millisecond),
+				RemoteAddr: r.RemoteAddr,
+				UserAgent:  r.UserAgent(),
+			}
+			if match := web.GetMatch(*c); match.Pattern != nil {
+				record.Pattern = fmt.Sprint(match.RawPattern())
+			}
+			sl.write(record)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func (sl *structuredLogger) write(record LogRecord) {
+	var buf bytes.Buffer
+	if sl.format == LogLogfmt {
+		writeLogfmt(&buf, record)
+	} else {
+		// LogRecord can always be marshaled.
+		b, _ := json.Marshal(record)
+		buf.Write(b)
+	}
+	buf.WriteByte('\n')
+
+	sl.mu.Lock()
+	sl.out.Write(buf.Bytes())
+	sl.mu.Unlock()
+}
+
+func writeLogfmt(buf *bytes.Buffer, record LogRecord) {
+	pairs := []struct {
+		k, v string
+	}{
+		{"time", record.Time.Format(time.RFC3339Nano)},
+		{"request_id", record.RequestID},
+		{"method", record.Method},
+		{"path", record.Path},
+		{"pattern", record.Pattern},
+		{"status", strconv.Itoa(record.Status)},
+		{"bytes", strconv.Itoa(record.Bytes)},
+		{"latency_ms", strconv.FormatFloat(record.LatencyMS, 'f', 3, 64)},
+		{"remote_addr", record.RemoteAddr},
+		{"user_agent", record.UserAgent},
+	}
+	for i, p := range pairs {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(p.k)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(p.v))
+	}
+}
+
+// logfmtValue quotes the given value if it would otherwise be ambiguous.
+func logfmtValue(v string) string {
+	if v == "" {
+		return `""`
+	}
+	for i := 0; i < len(v); i++ {
+		if b := v[i]; b <= ' ' || b == '=' || b == '"' || b >= 0x7f {
+			return strconv.Quote(v)
+		}
+	}
+	return v
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zenazn/goji/web"
+)
+
+func structuredMux(format LogFormat, out *bytes.Buffer) *web.Mux {
+	m := web.New()
+	m.Use(RequestID)
+	m.Use(m.Router)
+	m.Use(NewStructuredLogger(StructuredLoggerOptions{
+		Format: format,
+		Output: out,
+	}))
+	m.Get("/hello/:name", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+	return m
+}
+
+func TestStructuredLoggerJSON(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := structuredMux(LogJSON, &out)
+
+	r, _ := http.NewRequest("GET", "/hello/carl", nil)
+	r.RemoteAddr = "1.2.3.4:5678"
+	r.Header.Set("User-Agent", "test agent")
+	m.ServeHTTP(httptest.NewRecorder(), r)
+
+	if !strings.HasSuffix(out.String(), "}\n") ||
+		strings.Count(out.String(), "\n") != 1 {
+		t.Fatalf("Expected a single record, got %q", out.String())
+	}
+	var record LogRecord
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Unable to decode %q: %v", out.String(), err)
+	}
+	if !strings.HasPrefix(record.RequestID, prefix) {
+		t.Errorf("Unexpected request ID %q", record.RequestID)
+	}
+	if record.Method != "GET" || record.Path != "/hello/carl" ||
+		record.Pattern != "/hello/:name" || record.Status != 201 ||
+		record.Bytes != 5 || record.RemoteAddr != "1.2.3.4:5678" ||
+		record.UserAgent != "test agent" || record.LatencyMS < 0 ||
+		record.Time.IsZero() {
+		t.Errorf("Unexpected record %+v", record)
+	}
+}
+
+func TestStructuredLoggerLogfmt(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	m := structuredMux(LogLogfmt, &out)
+
+	r, _ := http.NewRequest("GET", "/nope", nil)
+	r.RemoteAddr = "1.2.3.4:5678"
+	r.Header.Set("User-Agent", `say "hi"`)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+
+	line := out.String()
+	for _, s := range []string{
+		" method=GET ",
+		" path=/nope ",
+		` pattern="" `,
+		" status=404 ",
+		" remote_addr=1.2.3.4:5678 ",
+		` user_agent="say \"hi\""` + "\n",
+	} {
+		if !strings.Contains(line, s) {
+			t.Errorf("Expected %q in %q", s, line)
+		}
+	}
+	if !strings.HasPrefix(line, "time=") {
+		t.Errorf("Expected line to begin with time, got %q", line)
+	}
+}
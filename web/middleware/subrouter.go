@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/zenazn/goji/web"
 )
@@ -19,22 +21,34 @@ func (s subrouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		if ok {
 			oldpath := r.URL.Path
+			oldrawpath := r.URL.RawPath
+			oldescaped := r.URL.EscapedPath()
 			oldmatch := web.GetMatch(*s.c)
+			oldprefix, hadprefix := getMountPrefix(*s.c)
 			r.URL.Path = path
+			r.URL.RawPath = rawSuffix(oldrawpath, path)
 			if oldmatch.Handler != nil {
-				delete(s.c.Env, web.MatchKey)
+				deleteMatch(s.c)
 			}
+			setMountPrefix(s.c, oldprefix+strippedPrefix(oldescaped,
+				oldpath, r.URL))
 
 			defer func() {
 				r.URL.Path = oldpath
+				r.URL.RawPath = oldrawpath
 
 				if s.c.Env == nil {
 					return
 				}
 				if oldmatch.Handler != nil {
-					s.c.Env[web.MatchKey] = oldmatch
+					setMatch(s.c, oldmatch)
 				} else {
-					delete(s.c.Env, web.MatchKey)
+					deleteMatch(s.c)
+				}
+				if hadprefix {
+					setMountPrefix(s.c, oldprefix)
+				} else {
+					deleteMountPrefix(s.c)
 				}
 			}()
 		}
@@ -42,6 +56,37 @@ func (s subrouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.h.ServeHTTP(w, r)
 }
 
+// rawSuffix returns the suffix of the given escaped path which encodes the given
+// (unescaped) path, so that encoded characters like "%2F" survive sub-routing.
+// If there is no such suffix, the empty string is returned, and net/url will
+// choose an encoding itself.
+func rawSuffix(rawpath, path string) string {
+	if rawpath == "" || !strings.HasPrefix(path, "/") {
+		return ""
+	}
+	for i := len(rawpath) - 1; i >= 0; i-- {
+		if rawpath[i] != '/' {
+			continue
+		}
+		u, err := url.ParseRequestURI(rawpath[i:])
+		if err == nil && u.Path == path {
+			return rawpath[i:]
+		}
+	}
+	return ""
+}
+
+// strippedPrefix returns the escaped form of the prefix which was removed from
+// the request's path (given in both its escaped and unescaped forms) to produce
+// the URL u.
+func strippedPrefix(oldescaped, oldpath string, u *url.URL) string {
+	if escaped := u.EscapedPath(); strings.HasSuffix(oldescaped, escaped) {
+		return oldescaped[:len(oldescaped)-len(escaped)]
+	}
+	prefix := url.URL{Path: strings.TrimSuffix(oldpath, u.Path)}
+	return prefix.EscapedPath()
+}
+
 /*
 SubRouter is a helper middleware that makes writing sub-routers easier.
 
@@ -55,6 +100,16 @@ named "*", SubRouter also accepts the string "_". For instance, to duplicate the
 semantics of the string pattern "/foo/*", you might use the regular expression
 "^/foo(?P<_>/.*)$".
 
+If the request's URL has a RawPath (for instance, because it contains an encoded
+slash), SubRouter sets the RawPath to the corresponding suffix as well, so that
+sub-routers which match against escaped paths (see web.Mux.EscapedPaths)
+continue to work.
+
+SubRouter also records the prefix it strips (appended to any prefix stripped by
+an outer SubRouter) in the environment under web.MountPrefixKey, so that
+redirects generated by the sub-router's Mux (see web.Mux.PathPolicy) point at
+the full path.
+
 This middleware is Match-aware: it will un-set any explicit routing information
 contained in the Goji context in order to prevent routing loops when using
 explicit routing with sub-routers. See the documentation for Mux.Router for
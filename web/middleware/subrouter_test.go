@@ -26,3 +26,69 @@ func TestSubRouterMatch(t *testing.T) {
 	// This function will recurse forever if SubRouter + Match didn't work.
 	m.ServeHTTP(httptest.NewRecorder(), r)
 }
+
+func TestSubRouterEscapedPath(t *testing.T) {
+	m := web.New()
+	m.EscapedPaths(true)
+
+	ch := make(chan string, 1)
+	m2 := web.New()
+	m2.EscapedPaths(true)
+	m2.Use(SubRouter)
+	m2.Get("/:key", func(c web.C, w http.ResponseWriter, r *http.Request) {
+		ch <- c.URLParams["key"]
+	})
+
+	m.Get("/foo/*", m2)
+
+	r, err := http.NewRequest("GET", "/foo/a%2Fb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	if key := <-ch; key != "a/b" {
+		t.Errorf("Expected key a/b, got %q", key)
+	}
+	if r.URL.RawPath != "/foo/a%2Fb" {
+		t.Errorf("Expected RawPath to be restored, got %q", r.URL.RawPath)
+	}
+}
+
+func TestSubRouterRedirect(t *testing.T) {
+	t.Parallel()
+	inner := web.New()
+	inner.Use(SubRouter)
+	inner.PathPolicy(web.PathRedirect)
+	inner.Get("/users", http.NotFound)
+
+	middle := web.New()
+	middle.Use(SubRouter)
+	middle.Handle("/v1/*", inner)
+
+	m := web.New()
+	m.Handle("/api/*", inner)
+	m.Handle("/my files/*", inner)
+	m.Handle("/nested/*", middle)
+
+	tests := []struct {
+		path, location string
+	}{
+		{"/api/users/", "/api/users"},
+		{"/api//users", "/api/users"},
+		{"/my files/users/", "/my%20files/users"},
+		{"/nested/v1/users/", "/nested/v1/users"},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.URL.Path = test.path
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		if w.Code != http.StatusMovedPermanently {
+			t.Errorf("%s: expected 301, got %d", test.path, w.Code)
+		}
+		if loc := w.HeaderMap.Get("Location"); loc != test.location {
+			t.Errorf("%s: expected Location %q, got %q", test.path,
+				test.location, loc)
+		}
+	}
+}
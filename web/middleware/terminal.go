@@ -41,20 +41,35 @@ func init() {
 	// code.google.com/p/go.crypto/ssh/terminal, for instance, but as a
 	// heuristic for whether to print in color or in black-and-white, I'd
 	// really rather not.
-	fi, err := os.Stdout.Stat()
-	if err == nil {
-		m := os.ModeDevice | os.ModeCharDevice
-		isTTY = fi.Mode()&m == m
+	isTTY = isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether the given file is a character device, which we
+// assume means it's a TTY (see above).
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
 	}
+	m := os.ModeDevice | os.ModeCharDevice
+	return fi.Mode()&m == m
 }
 
 // colorWrite
 func cW(buf *bytes.Buffer, color []byte, s string, args ...interface{}) {
-	if isTTY {
+	colorWrite(buf, isTTY, color, s, args...)
+}
+
+// colorWrite writes in the given color if useColor is set, and in black and
+// white otherwise.
+func colorWrite(buf *bytes.Buffer, useColor bool, color []byte, s string,
+	args ...interface{}) {
+
+	if useColor {
 		buf.Write(color)
 	}
 	fmt.Fprintf(buf, s, args...)
-	if isTTY {
+	if useColor {
 		buf.Write(reset)
 	}
 }
@@ -12,10 +12,7 @@ const URLQueryKey string = "urlquery"
 // and store the resulting url.Values in the context.
 func URLQuery(c *web.C, h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		if c.Env == nil {
-			c.Env = make(map[interface{}]interface{})
-		}
-		c.Env[URLQueryKey] = r.URL.Query()
+		setURLQuery(c, r.URL.Query())
 
 		h.ServeHTTP(w, r)
 	}
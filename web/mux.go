@@ -17,6 +17,15 @@ Routes may be added using any of the HTTP verb functions (Get, Post, etc.), or
 through the generic Handle function. Goji's routing algorithm is very simple:
 routes are processed in the order they are added, and the first matching route
 will be executed. Routes match if their HTTP method and Pattern both match.
+Each of these functions returns a Route, which can be used to attach additional
+properties (such as a name) to the route that was added.
+
+Each of these functions also accepts an optional list of middleware, in any of
+the forms accepted by Use, which is run only for requests routed to that route
+(for instance, Get("/admin/stats", h, RequireAdmin)). Per-route middleware is
+run after the Mux's middleware, and since routing has already taken place,
+URLParams will already be populated when it is run. Like the Mux's own
+middleware stack, per-route stacks are cached and reused between requests.
 */
 type Mux struct {
 	ms mStack
@@ -31,8 +40,10 @@ func New() *Mux {
 			pool:  makeCPool(),
 		},
 		rt: router{
-			routes:   make([]route, 0),
-			notFound: parseHandler(http.NotFound),
+			routes:           make([]route, 0),
+			notFound:         HandlerFunc(defaultNotFound),
+			methodNotAllowed: HandlerFunc(defaultMethodNotAllowed),
+			methods:          validMethodsMap,
 		},
 	}
 	mux.ms.router = &mux.rt
@@ -97,7 +108,7 @@ func (rm routerMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if rm.c.Env == nil {
 		rm.c.Env = make(map[interface{}]interface{}, 1)
 	}
-	rm.c.Env[MatchKey] = rm.m.rt.getMatch(rm.c, w, r)
+	setEnvMatch(rm.c, rm.m.rt.getMatch(rm.c, w, r))
 	rm.h.ServeHTTP(w, r)
 }
 
@@ -131,20 +142,20 @@ doesn't strip this prefix from the path before forwarding it on (e.g., the
 handler will see the full path, including the "/admin/" part), but this
 functionality can easily be performed by an extra middleware layer.
 */
-func (m *Mux) Handle(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mALL, handler)
+func (m *Mux) Handle(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mALL, handler, middleware)
 }
 
 // Connect dispatches to the given handler when the pattern matches and the HTTP
 // method is CONNECT.
-func (m *Mux) Connect(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mCONNECT, handler)
+func (m *Mux) Connect(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mCONNECT, handler, middleware)
 }
 
 // Delete dispatches to the given handler when the pattern matches and the HTTP
 // method is DELETE.
-func (m *Mux) Delete(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mDELETE, handler)
+func (m *Mux) Delete(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mDELETE, handler, middleware)
 }
 
 // Get dispatches to the given handler when the pattern matches and the HTTP
@@ -154,54 +165,188 @@ func (m *Mux) Delete(pattern PatternType, handler HandlerType) {
 // take care of all the fiddly bits for you. If you wish to provide an alternate
 // implementation of HEAD, you should add a handler explicitly and place it
 // above your GET handler.
-func (m *Mux) Get(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mGET|mHEAD, handler)
+func (m *Mux) Get(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mGET|mHEAD, handler, middleware)
 }
 
 // Head dispatches to the given handler when the pattern matches and the HTTP
 // method is HEAD.
-func (m *Mux) Head(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mHEAD, handler)
+func (m *Mux) Head(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mHEAD, handler, middleware)
 }
 
 // Options dispatches to the given handler when the pattern matches and the HTTP
 // method is OPTIONS.
-func (m *Mux) Options(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mOPTIONS, handler)
+func (m *Mux) Options(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mOPTIONS, handler, middleware)
 }
 
 // Patch dispatches to the given handler when the pattern matches and the HTTP
 // method is PATCH.
-func (m *Mux) Patch(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mPATCH, handler)
+func (m *Mux) Patch(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mPATCH, handler, middleware)
 }
 
 // Post dispatches to the given handler when the pattern matches and the HTTP
 // method is POST.
-func (m *Mux) Post(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mPOST, handler)
+func (m *Mux) Post(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mPOST, handler, middleware)
 }
 
 // Put dispatches to the given handler when the pattern matches and the HTTP
 // method is PUT.
-func (m *Mux) Put(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mPUT, handler)
+func (m *Mux) Put(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mPUT, handler, middleware)
 }
 
 // Trace dispatches to the given handler when the pattern matches and the HTTP
 // method is TRACE.
-func (m *Mux) Trace(pattern PatternType, handler HandlerType) {
-	m.rt.handleUntyped(pattern, mTRACE, handler)
+func (m *Mux) Trace(pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, mTRACE, handler, middleware)
+}
+
+// Method dispatches to the given handler when the pattern matches and the HTTP
+// method is the given one. This is most useful for extension methods, such as
+// WebDAV's PROPFIND or MKCOL, which are routed separately from each other and
+// from the standard methods, and which appear in the list of valid methods
+// given to the NotFound and MethodNotAllowed handlers. Method names are case
+// sensitive. Unlike Get, Method("GET", ...) does not also serve HEAD requests.
+func (m *Mux) Method(method string, pattern PatternType, handler HandlerType, middleware ...MiddlewareType) *Route {
+	return m.rt.handleUntyped(pattern, m.rt.method(method), handler,
+		middleware)
+}
+
+// URL returns a path that would be matched by the route with the given name
+// (see Route.Name), substituting in the given URL parameters. String patterns
+// and regular expressions consisting only of literals and capturing groups are
+// supported. An error is returned if no such route exists, or if a parameter
+// is missing or does not satisfy the route's pattern.
+func (m *Mux) URL(name string, params map[string]string) (string, error) {
+	return m.rt.url(name, params)
+}
+
+// NotFound sets the fallback (i.e., 404) handler for this mux. Requests whose
+// path matches a route but whose HTTP method does not are instead handled by
+// the MethodNotAllowed handler.
+//
+// If a route would have matched but for one of its Predicates, the context
+// environment variable "goji.web.PredicateStatus" (also available as the
+// constant PredicateStatusKey) will be set to the status code associated with
+// the first Predicate that failed (for instance, 406 or 415), preferring any
+// status over 404. The default NotFound handler responds with this status code
+// if it is set. As a convenience, ValidMethodsKey will also be set in this case
+// (see MethodNotAllowed).
+func (m *Mux) NotFound(handler HandlerType) {
+	m.rt.notFound = parseHandler(handler)
 }
 
-// NotFound sets the fallback (i.e., 404) handler for this mux.
+// MethodNotAllowed sets the handler which is called when a request's path
+// matches at least one route, but its HTTP method does not match any of them.
 //
 // As a convenience, the context environment variable "goji.web.validMethods"
 // (also available as the constant ValidMethodsKey) will be set to the list of
 // HTTP methods that could have been routed had they been provided on an
-// otherwise identical request.
-func (m *Mux) NotFound(handler HandlerType) {
-	m.rt.notFound = parseHandler(handler)
+// otherwise identical request. The default handler responds with a 405 (Method
+// Not Allowed), setting the Allow header to this list.
+func (m *Mux) MethodNotAllowed(handler HandlerType) {
+	m.rt.methodNotAllowed = parseHandler(handler)
+}
+
+// Remove removes a route from the Mux. The route removed is the first one added
+// which responds to the given HTTP method (so "HEAD" will also find routes
+// added with Get, and any method will find routes added with Handle), and
+// whose pattern is identical to the given one. Regular expressions are
+// identical if they have the same source, and other Patterns are identical if
+// they are equal. An error is returned if no such route exists.
+//
+// Routes may be removed while the Mux is serving requests: requests which have
+// already been routed are unaffected, and subsequent requests will not be
+// routed to the removed route.
+func (m *Mux) Remove(method string, pattern PatternType) error {
+	return m.rt.remove(method, ParsePattern(pattern))
+}
+
+// Replace replaces the handler of a route in the Mux, wrapping it in the given
+// middleware. The route is chosen in the same way as Remove, and keeps its
+// place in the routing order as well as any name, metadata, or Predicates that
+// were attached to it. If the route was added through a Group, the Group's
+// middleware continues to run before (outside) the given middleware. An error
+// is returned if no such route exists.
+//
+// As with Remove, routes may be replaced while the Mux is serving requests.
+func (m *Mux) Replace(method string, pattern PatternType, handler HandlerType, middleware ...MiddlewareType) error {
+	return m.rt.replace(method, ParsePattern(pattern), parseHandler(handler),
+		middleware)
+}
+
+// RadixRouting selects between Goji's two routing algorithms. By default,
+// routes are compiled into a bytecode state machine which is able to quickly
+// skip routes whose prefix does not match the request, but which must examine
+// each route sharing a matching prefix in turn. When enabled, string patterns
+// are instead indexed by path segment in a radix tree, which is considerably
+// faster for applications with large numbers of routes sharing common
+// prefixes (for instance, a REST API in which every route begins with
+// "/api/"). Regular expressions and custom Patterns are supported by both
+// algorithms, and both provide identical routing semantics.
+//
+// It is illegal to call this function concurrently with active requests.
+func (m *Mux) RadixRouting(enabled bool) {
+	m.rt.setRadix(enabled)
+}
+
+// PathPolicy sets how the Mux treats requests whose paths do not match any
+// route, but which would have matched had they been written canonically: with
+// repeated slashes collapsed, "." and ".." elements resolved, or a trailing
+// slash added or removed. For instance, if only "/users" has been added, a
+// request for "/users/" or "//users" is by default (PathStrict) not found, but
+// can instead be redirected to "/users" (PathRedirect) or served by the
+// "/users" route directly (PathLenient). Paths are only canonicalized when the
+// request would not otherwise have been routed. If the canonical path only
+// matches routes for other methods, the request is treated as if it had been
+// made for the canonical path, and is answered with a 405 (Method Not Allowed).
+//
+// It is illegal to call this function concurrently with active requests.
+func (m *Mux) PathPolicy(policy PathPolicy) {
+	m.rt.pathPolicy = policy
+}
+
+// EscapedPaths controls whether routes are matched against the request's
+// escaped path (see net/url.URL.EscapedPath) rather than its decoded path. This
+// allows URL parameters to contain encoded characters that would otherwise be
+// significant to the pattern, most notably "/" (as "%2F"). Each URL parameter,
+// including the "*" wildcard, is unescaped when it is bound into the
+// context's URLParams. Note that literal portions of patterns must then match
+// the escaped path exactly, and that parameter constraints are checked against
+// the escaped value.
+//
+// It is illegal to call this function concurrently with active requests.
+func (m *Mux) EscapedPaths(enabled bool) {
+	m.rt.escapedPaths = enabled
+}
+
+// AttachContext controls whether the Mux attaches the Goji context to each
+// request's context.Context (see NewContext) before calling the handler it was
+// routed to, so that net/http handlers, and the libraries they call, can
+// retrieve it using FromContext. Since this allocates a new context.Context and
+// a copy of the request for every request, it is disabled by default. It has no
+// effect before Go 1.7.
+//
+// It is illegal to call this function concurrently with active requests.
+func (m *Mux) AttachContext(enabled bool) {
+	m.rt.attachC = enabled
+}
+
+// CaseInsensitive causes the Mux to match the literal portions of every string
+// pattern case-insensitively (see the CaseInsensitive function), for instance
+// so that "/About" and "/ABOUT" are both routed to "/about". URL parameters are
+// bound using the case they were given in the request. Prefixes of other
+// Patterns are also compared case-insensitively, although whether they match
+// is up to the Pattern itself.
+//
+// CaseInsensitive must be called before any routes are added, and cannot be
+// undone.
+func (m *Mux) CaseInsensitive() {
+	m.rt.setFold()
 }
 
 // Compile compiles the list of routes into bytecode. This only needs to be done
@@ -211,3 +356,20 @@ func (m *Mux) NotFound(handler HandlerType) {
 func (m *Mux) Compile() {
 	m.rt.compile()
 }
+
+// CompileStrict compiles the Mux's routes (see Compile), and additionally
+// checks for routes which can never be matched because a route added before
+// them will always match first. For instance, if "/users/:id" is added before
+// "/users/new" with an overlapping set of HTTP methods, the latter can never be
+// reached. Exact duplicates of earlier routes are also reported. If any such
+// routes are found, a ShadowError describing them is returned.
+//
+// Goji can only reason about string patterns and duplicated regular
+// expressions: routes using other Pattern types are never reported.
+func (m *Mux) CompileStrict() error {
+	m.rt.compile()
+	if shadowed := m.rt.findShadowed(); len(shadowed) != 0 {
+		return shadowed
+	}
+	return nil
+}
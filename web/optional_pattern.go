@@ -0,0 +1,150 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// optionalPattern is a string pattern containing optional groups, like
+// "/archive/:year(/:month)?". It is expanded into a stringPattern for each
+// combination of present and absent groups, which are tried in order (groups
+// which are present before those which are absent).
+type optionalPattern struct {
+	raw    string
+	prefix string
+	alts   []stringPattern
+}
+
+func (o optionalPattern) Prefix() string {
+	return o.prefix
+}
+func (o optionalPattern) foldPrefix() string {
+	prefix := o.alts[0].foldPrefix()
+	for _, alt := range o.alts[1:] {
+		prefix = commonPrefix(prefix, alt.foldPrefix())
+	}
+	return prefix
+}
+func (o optionalPattern) Match(r *http.Request, c *C) bool {
+	for _, alt := range o.alts {
+		if alt.Match(r, c) {
+			return true
+		}
+	}
+	return false
+}
+func (o optionalPattern) Run(r *http.Request, c *C) {
+	for _, alt := range o.alts {
+		if alt.Match(r, c) {
+			alt.Run(r, c)
+			return
+		}
+	}
+}
+
+// buildURL uses the first alternative for which every parameter is available.
+// If there is none, the error from the most complete alternative is returned.
+func (o optionalPattern) buildURL(params map[string]string) (string, error) {
+	var first error
+	for _, alt := range o.alts {
+		path, err := alt.buildURL(params)
+		if err == nil {
+			return path, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return "", first
+}
+
+func (o optionalPattern) String() string {
+	return fmt.Sprintf("optionalPattern(%q)", o.raw)
+}
+
+func (o optionalPattern) Raw() string {
+	return o.raw
+}
+
+// optionalGroup returns the indices of the parentheses surrounding the first
+// optional group (i.e., one followed by a "?") in the given pattern, or -1, -1
+// if there is none. Parentheses inside constraints are ignored.
+func optionalGroup(s string) (int, int) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			if e := closingBrace(s, i); e != -1 {
+				i = e
+			}
+		case '(':
+			if e := closingParen(s, i); e != -1 &&
+				e+1 < len(s) && s[e+1] == '?' {
+				return i, e
+			}
+		}
+	}
+	return -1, -1
+}
+
+// closingParen returns the index of the parenthesis which closes the one at
+// s[i], or -1 if it is never closed.
+func closingParen(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			if e := closingBrace(s, i); e != -1 {
+				i = e
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// expandOptional returns every string pattern described by a pattern with
+// optional groups, in the order they should be tried.
+func expandOptional(s string) []string {
+	a, b := optionalGroup(s)
+	if a == -1 {
+		return []string{s}
+	}
+	head := s[:a]
+	inner := expandOptional(s[a+1 : b])
+	rest := expandOptional(s[b+2:])
+
+	out := make([]string, 0, (len(inner)+1)*len(rest))
+	for _, in := range inner {
+		for _, r := range rest {
+			out = append(out, head+in+r)
+		}
+	}
+	for _, r := range rest {
+		out = append(out, head+r)
+	}
+	return out
+}
+
+// parseString parses a string pattern, which may contain optional groups.
+func parseString(s string) Pattern {
+	alts := expandOptional(s)
+	if len(alts) == 1 {
+		return parseStringPattern(s)
+	}
+
+	o := optionalPattern{raw: s, alts: make([]stringPattern, len(alts))}
+	for i, alt := range alts {
+		o.alts[i] = parseStringPattern(alt)
+	}
+	o.prefix = o.alts[0].Prefix()
+	for _, alt := range o.alts[1:] {
+		o.prefix = commonPrefix(o.prefix, alt.Prefix())
+	}
+	return o
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMissingParam is the error wrapped by a ParamError when the requested URL
+// parameter was not bound during routing.
+var ErrMissingParam = errors.New("missing parameter")
+
+// ParamError is the error returned by C's typed URL parameter accessors (for
+// instance, ParamInt) when a parameter is missing or cannot be converted.
+type ParamError struct {
+	// Name is the name of the URL parameter.
+	Name string
+	// Value is the parameter's value, which will be empty if it was
+	// missing.
+	Value string
+	// Type is a description of the type the value was converted to, such
+	// as "int".
+	Type string
+	// Err is the underlying error. It is ErrMissingParam if the parameter
+	// was missing.
+	Err error
+}
+
+func (e *ParamError) Error() string {
+	if e.Err == ErrMissingParam {
+		return fmt.Sprintf("missing URL parameter %q", e.Name)
+	}
+	return fmt.Sprintf("URL parameter %q (%q) is not a valid %s", e.Name,
+		e.Value, e.Type)
+}
+
+// Unwrap returns the underlying error, so that errors.Is(err, ErrMissingParam)
+// reports whether a parameter was missing.
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+func (c C) param(name, typ string) (string, error) {
+	v, ok := c.URLParams[name]
+	if !ok {
+		return "", &ParamError{Name: name, Type: typ, Err: ErrMissingParam}
+	}
+	return v, nil
+}
+
+// ParamInt returns the value of the named URL parameter as an int. If the
+// parameter is missing or is not a base 10 integer, a *ParamError is returned.
+// Using a constraint like "/users/:id{int}" ensures that parameters are
+// well-formed before they are routed, but ParamInt still reports values which
+// are too large for an int.
+func (c C) ParamInt(name string) (int, error) {
+	v, err := c.param(name, "int")
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &ParamError{Name: name, Value: v, Type: "int", Err: err}
+	}
+	return i, nil
+}
+
+// ParamUUID returns the value of the named URL parameter, which must be a UUID
+// in its standard hyphenated form, such as
+// "123e4567-e89b-12d3-a456-426614174000". The UUID is returned in lowercase. If
+// the parameter is missing or is not a UUID, a *ParamError is returned.
+func (c C) ParamUUID(name string) (string, error) {
+	v, err := c.param(name, "UUID")
+	if err != nil {
+		return "", err
+	}
+	if !isUUID(v) {
+		return "", &ParamError{Name: name, Value: v, Type: "UUID",
+			Err: errors.New("malformed UUID")}
+	}
+	return strings.ToLower(v), nil
+}
+
+// ParamTime returns the value of the named URL parameter parsed as a time using
+// the given layout (see time.Parse). If the parameter is missing or cannot be
+// parsed, a *ParamError is returned.
+func (c C) ParamTime(name, layout string) (time.Time, error) {
+	v, err := c.param(name, "time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, v)
+	if err != nil {
+		return time.Time{}, &ParamError{Name: name, Value: v,
+			Type: "time", Err: err}
+	}
+	return t, nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !ishex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// BadParam responds to the request with a 400 (Bad Request) describing the
+// given error, which is typically a *ParamError returned by one of C's typed
+// URL parameter accessors.
+func BadParam(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var paramsC = C{URLParams: map[string]string{
+	"id":   "42",
+	"big":  "99999999999999999999",
+	"name": "carl",
+	"uuid": "123E4567-e89b-12d3-a456-426614174000",
+	"date": "2015-04-01",
+}}
+
+func TestParamInt(t *testing.T) {
+	t.Parallel()
+	if i, err := paramsC.ParamInt("id"); err != nil || i != 42 {
+		t.Errorf("Expected 42, got %d (%v)", i, err)
+	}
+	for _, name := range []string{"big", "name", "missing"} {
+		if _, err := paramsC.ParamInt(name); err == nil {
+			t.Errorf("Expected an error for %q", name)
+		} else if pe, ok := err.(*ParamError); !ok || pe.Name != name {
+			t.Errorf("Expected a ParamError for %q, got %v", name,
+				err)
+		}
+	}
+}
+
+func TestParamUUID(t *testing.T) {
+	t.Parallel()
+	u, err := paramsC.ParamUUID("uuid")
+	if err != nil || u != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("Unexpected UUID %q (%v)", u, err)
+	}
+	if _, err := paramsC.ParamUUID("name"); err == nil {
+		t.Error("Expected an error for a malformed UUID")
+	}
+	c := C{URLParams: map[string]string{
+		"uuid": "123e4567-e89b-12d3-a456_426614174000",
+	}}
+	if _, err := c.ParamUUID("uuid"); err == nil {
+		t.Error("Expected an error for a misplaced separator")
+	}
+}
+
+func TestParamTime(t *testing.T) {
+	t.Parallel()
+	d, err := paramsC.ParamTime("date", "2006-01-02")
+	expected := time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err != nil || !d.Equal(expected) {
+		t.Errorf("Expected %v, got %v (%v)", expected, d, err)
+	}
+	if _, err := paramsC.ParamTime("name", "2006-01-02"); err == nil {
+		t.Error("Expected an error for a malformed time")
+	}
+}
+
+func TestParamErrors(t *testing.T) {
+	t.Parallel()
+	_, err := C{}.ParamInt("id")
+	if pe, ok := err.(*ParamError); !ok || pe.Err != ErrMissingParam {
+		t.Errorf("Expected a missing ParamError, got %v", err)
+	}
+	if msg := err.Error(); msg != `missing URL parameter "id"` {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if !errors.Is(err, ErrMissingParam) {
+		t.Errorf("Expected %v to wrap ErrMissingParam", err)
+	}
+
+	_, err = paramsC.ParamInt("name")
+	if !errors.Is(err, strconv.ErrSyntax) || errors.Is(err, ErrMissingParam) {
+		t.Errorf("Expected %v to wrap strconv.ErrSyntax", err)
+	}
+	w := httptest.NewRecorder()
+	BadParam(w, err)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", w.Code)
+	}
+	expected := `URL parameter "name" ("carl") is not a valid int` + "\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+	}
+}
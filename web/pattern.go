@@ -50,7 +50,7 @@ func ParsePattern(raw PatternType) Pattern {
 	case *regexp.Regexp:
 		return parseRegexpPattern(v)
 	case string:
-		return parseStringPattern(v)
+		return parseString(v)
 	default:
 		log.Fatalf(unknownPattern, v)
 		panic("log.Fatalf does not return")
@@ -126,6 +126,37 @@ var patternTests = []struct {
 			pt("/a/cat.", false, nil),
 			pt("/a/cat/dog.gif", false, nil),
 		}},
+	{parseStringPattern("/users/:id{int}"),
+		"/users/", []patternTest{
+			pt("/users/123", true, map[string]string{
+				"id": "123",
+			}),
+			pt("/users/-5", true, map[string]string{
+				"id": "-5",
+			}),
+			pt("/users/carl", false, nil),
+			pt("/users/12a", false, nil),
+			pt("/users/", false, nil),
+		}},
+	{parseStringPattern("/files/:name{[a-z0-9-]+}.:ext{[a-z]{2,4}}"),
+		"/files/", []patternTest{
+			pt("/files/my-notes.txt", true, map[string]string{
+				"name": "my-notes",
+				"ext":  "txt",
+			}),
+			pt("/files/My-Notes.txt", false, nil),
+			pt("/files/notes.t", false, nil),
+			pt("/files/notes.markdown", false, nil),
+		}},
+	{parseStringPattern("/u/:uid{uuid}/:tab"),
+		"/u/", []patternTest{
+			pt("/u/123e4567-e89b-12d3-a456-426614174000/posts", true,
+				map[string]string{
+					"uid": "123e4567-e89b-12d3-a456-426614174000",
+					"tab": "posts",
+				}),
+			pt("/u/123e4567/posts", false, nil),
+		}},
 
 	// String prefix tests
 	{parseStringPattern("/user/:user/*"),
@@ -154,6 +185,114 @@ var patternTests = []struct {
 			}),
 			pt("/user/bob/enemies", false, nil),
 		}},
+
+	// Catch-all tests
+	{parseStringPattern("/repos/:owner/:repo/blob/*path"),
+		"/repos/", []patternTest{
+			pt("/repos/zenazn/goji/blob/web/web.go", true,
+				map[string]string{
+					"owner": "zenazn",
+					"repo":  "goji",
+					"path":  "web/web.go",
+				}),
+			pt("/repos/zenazn/goji/blob/README.md", true,
+				map[string]string{
+					"owner": "zenazn",
+					"repo":  "goji",
+					"path":  "README.md",
+				}),
+			pt("/repos/zenazn/goji/blob/", false, nil),
+			pt("/repos/zenazn/blob/web.go", false, nil),
+		}},
+	{parseStringPattern("/files/*path/edit"),
+		"/files/", []patternTest{
+			pt("/files/a/b/edit", true, map[string]string{
+				"path": "a/b",
+			}),
+			pt("/files/a/edit/edit", true, map[string]string{
+				"path": "a/edit",
+			}),
+			pt("/files/edit", false, nil),
+			pt("/files/a/b", false, nil),
+		}},
+	{parseStringPattern("/docs/*path{.*\\.md}/:rev"),
+		"/docs/", []patternTest{
+			pt("/docs/a/b.md/3", true, map[string]string{
+				"path": "a/b.md",
+				"rev":  "3",
+			}),
+			pt("/docs/a/b.txt/3", false, nil),
+		}},
+
+	// Case-insensitive tests
+	{CaseInsensitive("/About/:name.TXT"),
+		"/", []patternTest{
+			pt("/about/Carl.txt", true, map[string]string{
+				"name": "Carl",
+			}),
+			pt("/ABOUT/Carl.Txt", true, map[string]string{
+				"name": "Carl",
+			}),
+			pt("/abut/Carl.txt", false, nil),
+		}},
+	{CaseInsensitive("/1/Help(/:topic)?"),
+		"/1/", []patternTest{
+			pt("/1/HELP", true, nil),
+			pt("/1/help/Routing", true, map[string]string{
+				"topic": "Routing",
+			}),
+		}},
+
+	// Optional group tests
+	{parseString("/archive/:year(/:month)?"),
+		"/archive/", []patternTest{
+			pt("/archive/2015", true, map[string]string{
+				"year": "2015",
+			}),
+			pt("/archive/2015/04", true, map[string]string{
+				"year":  "2015",
+				"month": "04",
+			}),
+			pt("/archive/2015/", false, nil),
+			pt("/archive/", false, nil),
+		}},
+	{parseString("/a(/b(/:c)?)?/d"),
+		"/a/", []patternTest{
+			pt("/a/d", true, nil),
+			pt("/a/b/d", true, nil),
+			pt("/a/b/c/d", true, map[string]string{
+				"c": "c",
+			}),
+			pt("/a/c/d", false, nil),
+		}},
+	{parseString("(/en)?/about"),
+		"/", []patternTest{
+			pt("/about", true, nil),
+			pt("/en/about", true, nil),
+			pt("/fr/about", false, nil),
+		}},
+}
+
+func TestExpandOptional(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"/a", []string{"/a"}},
+		{"/a(/b)?", []string{"/a/b", "/a"}},
+		{"/a(/b)?(/c)?", []string{"/a/b/c", "/a/b", "/a/c", "/a"}},
+		{"/a(/b(/c)?)?", []string{"/a/b/c", "/a/b", "/a"}},
+		{"/:id{(a|b)}", []string{"/:id{(a|b)}"}},
+		{"/f(x)", []string{"/f(x)"}},
+	}
+	for _, test := range tests {
+		actual := expandOptional(test.pattern)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("expandOptional(%q): expected %q, got %q",
+				test.pattern, test.expected, actual)
+		}
+	}
 }
 
 func TestPatterns(t *testing.T) {
@@ -0,0 +1,157 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// The key used to communicate to the NotFound handler the HTTP status code of
+// the first Predicate which failed on a route that otherwise would have
+// matched. Since 404 is the least informative status, the first status other
+// than 404 is preferred if one exists. The value is an int.
+const PredicateStatusKey = "goji.web.PredicateStatus"
+
+// A Predicate is an additional condition that a request must satisfy in order
+// to match a route, beyond its method and Pattern. Predicates are attached to
+// routes using Route.Where.
+//
+// Several Predicates are provided, and it is simple to write your own.
+type Predicate interface {
+	// Check returns true if the request satisfies the Predicate. Like
+	// Pattern's Match function, Check should not modify its arguments,
+	// and it may be called several times over the course of routing a
+	// request.
+	Check(r *http.Request, c *C) bool
+	// Status returns the HTTP status code which best describes a request
+	// that does not satisfy the Predicate, for instance 406 (Not
+	// Acceptable) if the request cannot accept a particular response type.
+	// If no other route matches the request, this status code is made
+	// available to the NotFound handler (see PredicateStatusKey).
+	Status() int
+}
+
+// Where attaches the given Predicates to the route. The route will only match
+// requests which satisfy all of its Predicates, and requests which do not will
+// continue on to subsequent routes.
+func (r *Route) Where(predicates ...Predicate) *Route {
+	r.rt.modify(r.id, func(rte *route) {
+		ps := make([]Predicate, 0, len(rte.predicates)+len(predicates))
+		ps = append(ps, rte.predicates...)
+		rte.predicates = append(ps, predicates...)
+	})
+	return r
+}
+
+type predicateFunc struct {
+	fn     func(r *http.Request) bool
+	status int
+}
+
+func (p predicateFunc) Check(r *http.Request, c *C) bool {
+	return p.fn(r)
+}
+func (p predicateFunc) Status() int {
+	return p.status
+}
+
+// Header returns a Predicate which requires that the request contain the given
+// header with the given value. If value is empty, any value is accepted.
+func Header(name, value string) Predicate {
+	name = http.CanonicalHeaderKey(name)
+	return predicateFunc{func(r *http.Request) bool {
+		values, ok := r.Header[name]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}, http.StatusNotFound}
+}
+
+// Query returns a Predicate which requires that the request's query string
+// contain the given key with the given value. If value is empty, any value is
+// accepted.
+func Query(key, value string) Predicate {
+	return predicateFunc{func(r *http.Request) bool {
+		values, ok := r.URL.Query()[key]
+		if !ok {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}, http.StatusNotFound}
+}
+
+// Accept returns a Predicate which requires that the request be able to accept
+// a response of the given media type (e.g., "application/json"), according to
+// its Accept header. Requests which do not send an Accept header are assumed to
+// accept any media type. Requests which fail this Predicate are described with
+// the status 406 (Not Acceptable).
+func Accept(mediaType string) Predicate {
+	mediaType = strings.ToLower(mediaType)
+	return predicateFunc{func(r *http.Request) bool {
+		accept := r.Header.Get("Accept")
+		if accept == "" {
+			return true
+		}
+		for _, part := range strings.Split(accept, ",") {
+			mr, params, err := mime.ParseMediaType(part)
+			if err != nil || zeroWeight(params["q"]) {
+				continue
+			}
+			if mediaRangeMatches(mr, mediaType) {
+				return true
+			}
+		}
+		return false
+	}, http.StatusNotAcceptable}
+}
+
+// zeroWeight reports whether the given quality value (e.g., "0.000") rejects
+// the media range it is attached to.
+func zeroWeight(q string) bool {
+	if q == "" {
+		return false
+	}
+	w, err := strconv.ParseFloat(q, 64)
+	return err == nil && w == 0
+}
+
+func mediaRangeMatches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	if strings.HasSuffix(mediaRange, "/*") {
+		return strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1])
+	}
+	return false
+}
+
+// ContentType returns a Predicate which requires that the request's body be of
+// the given media type (e.g., "application/json"), according to its
+// Content-Type header. Parameters, like charset, are ignored. Requests which
+// fail this Predicate are described with the status 415 (Unsupported Media
+// Type).
+func ContentType(mediaType string) Predicate {
+	mediaType = strings.ToLower(mediaType)
+	return predicateFunc{func(r *http.Request) bool {
+		mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		return err == nil && mt == mediaType
+	}, http.StatusUnsupportedMediaType}
+}
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func predReq(method, url string, headers map[string]string) *http.Request {
+	r, _ := http.NewRequest(method, url, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+var predicateTests = []struct {
+	p     Predicate
+	r     *http.Request
+	match bool
+}{
+	{Header("X-Version", "2"), predReq("GET", "/", map[string]string{
+		"X-Version": "2",
+	}), true},
+	{Header("X-Version", "2"), predReq("GET", "/", map[string]string{
+		"X-Version": "1",
+	}), false},
+	{Header("X-Version", ""), predReq("GET", "/", map[string]string{
+		"X-Version": "1",
+	}), true},
+	{Header("X-Version", ""), predReq("GET", "/", nil), false},
+	{Query("format", "csv"), predReq("GET", "/?format=csv", nil), true},
+	{Query("format", "csv"), predReq("GET", "/?format=json", nil), false},
+	{Query("format", ""), predReq("GET", "/?format=json", nil), true},
+	{Query("format", ""), predReq("GET", "/", nil), false},
+	{Accept("application/json"), predReq("GET", "/", nil), true},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "text/html, application/json;q=0.9",
+	}), true},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "application/*",
+	}), true},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "*/*",
+	}), true},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "text/html",
+	}), false},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "application/json;q=0",
+	}), false},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "application/json;q=0.0",
+	}), false},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "application/json;q=0.000, text/html",
+	}), false},
+	{Accept("application/json"), predReq("GET", "/", map[string]string{
+		"Accept": "application/json;q=0.001",
+	}), true},
+	{ContentType("application/json"), predReq("POST", "/", map[string]string{
+		"Content-Type": "application/json; charset=utf-8",
+	}), true},
+	{ContentType("application/json"), predReq("POST", "/", map[string]string{
+		"Content-Type": "text/plain",
+	}), false},
+	{ContentType("application/json"), predReq("POST", "/", nil), false},
+}
+
+func TestPredicates(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range predicateTests {
+		if match := test.p.Check(test.r, &C{}); match != test.match {
+			t.Errorf("%d: expected %v, got %v", i, test.match, match)
+		}
+	}
+}
+
+func TestPredicateRouting(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 1)
+
+	m.Get("/report", chHandler(ch, "csv")).Where(Query("format", "csv"))
+	m.Get("/report", chHandler(ch, "v2")).
+		Where(Accept("application/vnd.v2+json"), Header("X-Beta", ""))
+	m.Post("/report", chHandler(ch, "json")).
+		Where(ContentType("application/json"))
+
+	tests := []struct {
+		r      *http.Request
+		status int
+		result string
+	}{
+		{predReq("GET", "/report?format=csv", nil), 200, "csv"},
+		{predReq("GET", "/report", map[string]string{
+			"Accept": "application/vnd.v2+json",
+			"X-Beta": "yes",
+		}), 200, "v2"},
+		{predReq("GET", "/report", map[string]string{
+			"Accept": "text/html",
+			"X-Beta": "yes",
+		}), http.StatusNotAcceptable, ""},
+		{predReq("POST", "/report", map[string]string{
+			"Content-Type": "application/json",
+		}), 200, "json"},
+		{predReq("POST", "/report", map[string]string{
+			"Content-Type": "text/plain",
+		}), http.StatusUnsupportedMediaType, ""},
+		{predReq("GET", "/nope", nil), http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, test.r)
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d",
+				test.r.Method, test.r.URL, test.status, w.Code)
+		}
+		if test.result == "" {
+			continue
+		}
+		if result := <-ch; result != test.result {
+			t.Errorf("%s %s: expected %q, got %q", test.r.Method,
+				test.r.URL, test.result, result)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+)
+
+/*
+This file implements an alternative to the bytecode router for applications
+with very large numbers of routes. The bytecode router is only able to branch on
+the literal prefixes of routes, so when many routes share a prefix (as in a
+large REST API, where every route begins with "/api/v1/") the router degrades to
+calling each of their Match functions in turn.
+
+The radix router instead indexes string patterns by whole path segments. Named
+parameters are stored in a single wildcard child of each node, and "/*"
+patterns are stored on the node at which their prefix ends. A lookup walks
+every branch of the tree that could plausibly match the path, collecting the
+indexes of the routes it finds.
+
+The tree is only ever used to discard routes which cannot possibly match: every
+candidate it returns is still run through its Pattern's Match function. Routes
+which the tree does not know how to index (regular expressions and custom
+Patterns) are instead checked against their prefix, as in a naive router.
+Candidates are then tried in the order in which they appear in the route list,
+so the radix router provides exactly the same ordering guarantees as the
+bytecode router.
+*/
+
+// paramSegment stands in for a named parameter when splitting string patterns
+// into segments. It cannot appear in a request path.
+const paramSegment = "\x00"
+
+type radixNode struct {
+	static map[string]*radixNode
+	param  *radixNode
+	// Routes whose pattern ends exactly at this node
+	routes []int
+	// "/*" routes whose prefix ends at this node
+	wildcard []int
+}
+
+type radixTree struct {
+	root radixNode
+	// If set, the tree is indexed by lowercased segments, and paths are
+	// lowercased before lookup.
+	fold bool
+	// Routes which could not be indexed
+	rest []int
+}
+
+// radixSegments splits a string pattern into path segments, returning false if
+// the pattern cannot be indexed by segment.
+func radixSegments(s stringPattern) ([]string, bool) {
+	src := strings.Join(s.literals, paramSegment)
+	if !strings.HasPrefix(src, "/") || s.multiSegment() {
+		return nil, false
+	}
+	segs := strings.Split(src[1:], "/")
+	if s.wildcard {
+		// The literal tail of a wildcard pattern always ends in a "/",
+		// which results in a trailing empty segment.
+		segs = segs[:len(segs)-1]
+	}
+	for i, seg := range segs {
+		if strings.Contains(seg, paramSegment) {
+			segs[i] = paramSegment
+		}
+	}
+	return segs, true
+}
+
+func (n *radixNode) child(seg string) *radixNode {
+	if seg == paramSegment {
+		if n.param == nil {
+			n.param = &radixNode{}
+		}
+		return n.param
+	}
+	if n.static == nil {
+		n.static = make(map[string]*radixNode)
+	}
+	c, ok := n.static[seg]
+	if !ok {
+		c = &radixNode{}
+		n.static[seg] = c
+	}
+	return c
+}
+
+func buildRadixTree(routes []route, fold bool) *radixTree {
+	t := &radixTree{fold: fold}
+	for i, r := range routes {
+		sp, ok := r.pattern.(stringPattern)
+		if !ok || (sp.fold && !fold) {
+			t.rest = append(t.rest, i)
+			continue
+		}
+		segs, ok := radixSegments(sp)
+		if !ok {
+			t.rest = append(t.rest, i)
+			continue
+		}
+
+		n := &t.root
+		for _, seg := range segs {
+			if fold {
+				seg = lowerASCII(seg)
+			}
+			n = n.child(seg)
+		}
+		if sp.wildcard {
+			n.wildcard = append(n.wildcard, i)
+		} else {
+			n.routes = append(n.routes, i)
+		}
+	}
+	return t
+}
+
+// lookup appends the indexes of every route in this subtree which might match
+// the given remainder of the path. The remainder is either empty or begins
+// with a "/".
+func (n *radixNode) lookup(path string, out []int) []int {
+	if path == "" {
+		return append(out, n.routes...)
+	}
+	out = append(out, n.wildcard...)
+
+	seg := path[1:]
+	if i := strings.IndexByte(seg, '/'); i != -1 {
+		seg = seg[:i]
+	}
+	rest := path[1+len(seg):]
+	if c, ok := n.static[seg]; ok {
+		out = c.lookup(rest, out)
+	}
+	if n.param != nil && seg != "" {
+		out = n.param.lookup(rest, out)
+	}
+	return out
+}
+
+func (t *radixTree) route(routes []route, m method, c *C, w http.ResponseWriter, r *http.Request) (method, int, *route) {
+	var methods method
+	var status int
+	path := r.URL.Path
+	if t.fold {
+		path = lowerASCII(path)
+	}
+
+	var buf [16]int
+	candidates := buf[:0]
+	if strings.HasPrefix(path, "/") {
+		candidates = t.root.lookup(path, candidates)
+	}
+	for _, i := range t.rest {
+		if strings.HasPrefix(path, routes[i].prefix) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	// Restore the route list's ordering. There usually aren't very many
+	// candidates, so insertion sort is just fine.
+	for i := 1; i < len(candidates); i++ {
+		for j := i; j > 0 && candidates[j-1] > candidates[j]; j-- {
+			candidates[j-1], candidates[j] = candidates[j],
+				candidates[j-1]
+		}
+	}
+
+	for _, i := range candidates {
+		if matchRoute(routes[i], m, &methods, &status, r, c) {
+			return 0, 0, &routes[i]
+		}
+	}
+	return methods, status, nil
+}
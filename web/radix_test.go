@@ -0,0 +1,162 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var radixRoutes = []struct {
+	method  string
+	pattern PatternType
+}{
+	{"GET", "/"},
+	{"GET", "/users"},
+	{"POST", "/users"},
+	{"GET", "/users/:id{int}"},
+	{"GET", "/users/new"},
+	{"GET", "/users/:name"},
+	{"PUT", "/users/:id"},
+	{"GET", "/users/:id/posts/:post"},
+	{"GET", "/users/:id/*"},
+	{"GET", regexp.MustCompile(`^/users/(?P<id>\d+)/avatar\.png$`)},
+	{"GET", "/files/:name.:ext"},
+	{"GET", "/repos/*path/edit"},
+	{"GET", "/archive/:year(/:month)?"},
+	{"GET", "/static/*"},
+	{"GET", "/*"},
+	{"DELETE", testPattern{}},
+}
+
+var radixRequests = []struct {
+	method string
+	path   string
+}{
+	{"GET", "/"},
+	{"GET", "/users"},
+	{"POST", "/users"},
+	{"DELETE", "/users"},
+	{"GET", "/users/"},
+	{"GET", "/users/123"},
+	{"GET", "/users/new"},
+	{"GET", "/users/carl"},
+	{"PUT", "/users/carl"},
+	{"PATCH", "/users/carl"},
+	{"GET", "/users/123/posts/4"},
+	{"GET", "/users/123/posts/"},
+	{"GET", "/users/123/avatar.png"},
+	{"GET", "/users/123/"},
+	{"GET", "/files/cat.gif"},
+	{"GET", "/files/cat"},
+	{"GET", "/repos/a/b/edit"},
+	{"GET", "/repos/edit"},
+	{"GET", "/archive/2015"},
+	{"GET", "/archive/2015/04"},
+	{"GET", "/static/"},
+	{"GET", "/static"},
+	{"GET", "/nope"},
+	{"GET", "nope"},
+	{"BOGUS", "/users/123"},
+}
+
+func radixMux(radix bool, ch chan int) *Mux {
+	m := New()
+	m.RadixRouting(radix)
+	notFound := func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- -len(c.Env[ValidMethodsKey].([]string))
+	}
+	m.NotFound(notFound)
+	m.MethodNotAllowed(notFound)
+	for i, route := range radixRoutes {
+		i := i
+		h := func(w http.ResponseWriter, r *http.Request) {
+			ch <- i
+		}
+		switch route.method {
+		case "GET":
+			m.Get(route.pattern, h)
+		case "POST":
+			m.Post(route.pattern, h)
+		case "PUT":
+			m.Put(route.pattern, h)
+		case "DELETE":
+			m.Delete(route.pattern, h)
+		}
+	}
+	return m
+}
+
+func TestRadixMatchesBytecode(t *testing.T) {
+	t.Parallel()
+	ch := make(chan int, 1)
+	bytecode := radixMux(false, ch)
+	radix := radixMux(true, ch)
+
+	for _, req := range radixRequests {
+		r, _ := http.NewRequest(req.method, req.path, nil)
+		bytecode.ServeHTTPC(C{Env: map[interface{}]interface{}{
+			ValidMethodsKey: []string{},
+		}}, httptest.NewRecorder(), r)
+		expected := <-ch
+		radix.ServeHTTPC(C{Env: map[interface{}]interface{}{
+			ValidMethodsKey: []string{},
+		}}, httptest.NewRecorder(), r)
+		actual := <-ch
+		if expected != actual {
+			t.Errorf("%s %s: bytecode routed to %d, radix to %d",
+				req.method, req.path, expected, actual)
+		}
+	}
+}
+
+func TestRadixRouteSelection(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.RadixRouting(true)
+	counter := 0
+	ichan := make(chan int, 1)
+	m.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		ichan <- -1
+	})
+
+	for i, s := range rsRoutes {
+		pat := rsPattern{
+			i:       i,
+			counter: &counter,
+			prefix:  s,
+			ichan:   ichan,
+		}
+		m.Get(pat, pat)
+	}
+
+	for _, test := range rsTests {
+		var n int
+		for counter, n = range test.results {
+			r, _ := http.NewRequest("GET", test.key, nil)
+			m.ServeHTTP(httptest.NewRecorder(), r)
+			actual := <-ichan
+			if n != actual {
+				t.Errorf("Expected %q @ %d to be %d, got %d",
+					test.key, counter, n, actual)
+			}
+		}
+	}
+}
+
+func TestRadixParams(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.RadixRouting(true)
+	ch := make(chan map[string]string, 1)
+	m.Get("/users/:id/posts/:post", func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- c.URLParams
+	})
+
+	r, _ := http.NewRequest("GET", "/users/carl/posts/7", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	params := <-ch
+	if params["id"] != "carl" || params["post"] != "7" {
+		t.Errorf("Unexpected params %v", params)
+	}
+}
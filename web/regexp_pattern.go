@@ -44,6 +44,61 @@ func (p regexpPattern) match(r *http.Request, c *C, dryrun bool) bool {
 	return true
 }
 
+// buildURL generates a path by substituting parameters into the regular
+// expression's capturing groups. This only works for regular expressions that
+// are otherwise entirely literal: we have no way of inventing a string that
+// satisfies "\d+" or "(a|b)" on the user's behalf.
+func (p regexpPattern) buildURL(params map[string]string) (string, error) {
+	re, err := syntax.Parse(p.re.String(), syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := p.reverse(&buf, re, params); err != nil {
+		return "", err
+	}
+	path := buf.String()
+	if !p.re.MatchString(path) {
+		return "", fmt.Errorf("generated path %q does not match %v",
+			path, p.re)
+	}
+	return escapePath(path), nil
+}
+
+func (p regexpPattern) reverse(buf *bytes.Buffer, re *syntax.Regexp, params map[string]string) error {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			buf.WriteRune(r)
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := p.reverse(buf, sub, params); err != nil {
+				return err
+			}
+		}
+	case syntax.OpCapture:
+		name := p.names[re.Cap]
+		v, ok := params[name]
+		if !ok {
+			return fmt.Errorf("missing parameter %q", name)
+		}
+		sub := `^(?:` + re.Sub[0].String() + `)$`
+		if ok, _ := regexp.MatchString(sub, v); !ok {
+			return fmt.Errorf("parameter %q (%q) does not match %s",
+				name, v, re.Sub[0])
+		}
+		buf.WriteString(v)
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText:
+		// Zero-width, so there's nothing to write
+	default:
+		return fmt.Errorf("cannot build URLs for %v: %s is not "+
+			"a literal or a capturing group", p.re, re)
+	}
+	return nil
+}
+
 func (p regexpPattern) String() string {
 	return fmt.Sprintf("regexpPattern(%v)", p.re)
 }
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestRemove(t *testing.T) {
+	t.Parallel()
+	for _, radix := range []bool{false, true} {
+		m := New()
+		m.RadixRouting(radix)
+		ch := make(chan string, 1)
+		m.Get("/a", chHandler(ch, "a")).Name("a")
+		m.Get("/a", chHandler(ch, "a2"))
+		m.Post("/b", chHandler(ch, "b"))
+		m.Get(regexp.MustCompile(`^/c$`), chHandler(ch, "c"))
+		m.NotFound(chHandler(ch, "404"))
+		m.MethodNotAllowed(chHandler(ch, "405"))
+
+		try := func(method, path, expected string) {
+			r, _ := http.NewRequest(method, path, nil)
+			m.ServeHTTP(httptest.NewRecorder(), r)
+			if actual := <-ch; actual != expected {
+				t.Errorf("%s %s (radix=%v): expected %q, got %q",
+					method, path, radix, expected, actual)
+			}
+		}
+
+		try("GET", "/a", "a")
+		if err := m.Remove("GET", "/a"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		try("GET", "/a", "a2")
+		if _, err := m.URL("a", nil); err == nil {
+			t.Error("Expected the removed route's name to be gone")
+		}
+
+		if err := m.Remove("GET", "/b"); err == nil {
+			t.Error("Expected an error removing GET /b")
+		}
+		if err := m.Remove("POST", "/b"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		try("POST", "/b", "404")
+
+		if err := m.Remove("HEAD", regexp.MustCompile(`^/c$`)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		try("GET", "/c", "404")
+
+		if err := m.Remove("GET", "/nope"); err == nil {
+			t.Error("Expected an error removing GET /nope")
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 2)
+	m.Get("/users/:name", chHandler(ch, "old")).Name("user")
+	m.Get("/users/*", chHandler(ch, "wildcard"))
+
+	err := m.Replace("GET", "/users/:name", chHandler(ch, "new"),
+		chanWare(ch, "middleware"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r, _ := http.NewRequest("GET", "/users/carl", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	assertOrder(t, ch, "middleware", "new")
+
+	if url, err := m.URL("user", map[string]string{"name": "carl"}); err != nil || url != "/users/carl" {
+		t.Errorf("Expected name to survive replacement, got %q (%v)",
+			url, err)
+	}
+
+	if err := m.Replace("POST", "/users/:name", http.NotFound); err == nil {
+		t.Error("Expected an error replacing POST /users/:name")
+	}
+}
+
+func TestReplaceGroup(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 3)
+	auth := func(h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+	g := m.Group("/admin", auth, chanWare(ch, "group"))
+	g.Get("/stats", chHandler(ch, "old"))
+
+	err := m.Replace("GET", "/admin/stats", chHandler(ch, "new"),
+		chanWare(ch, "route"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r, _ := http.NewRequest("GET", "/admin/stats", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected Group middleware to survive replacement, "+
+			"got %d", w.Code)
+	}
+
+	r.Header.Set("Authorization", "yes")
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	assertOrder(t, ch, "group", "route", "new")
+}
+
+func TestIdenticalPattern(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b      PatternType
+		identical bool
+	}{
+		{"/a", "/a", true},
+		{"/a", "/b", false},
+		{regexp.MustCompile(`^/a$`), regexp.MustCompile(`^/a$`), true},
+		{regexp.MustCompile(`^/a$`), "/a", false},
+		{testPattern{}, testPattern{}, true},
+	}
+	for _, test := range tests {
+		a, b := ParsePattern(test.a), ParsePattern(test.b)
+		if actual := identicalPattern(a, b); actual != test.identical {
+			t.Errorf("identicalPattern(%v, %v): expected %v", a, b,
+				test.identical)
+		}
+	}
+}
+
+func TestRemoveConcurrent(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.Get("/a", http.NotFound)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r, _ := http.NewRequest("GET", "/a", nil)
+				m.ServeHTTP(httptest.NewRecorder(), r)
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		m.Get("/b", http.NotFound)
+		if err := m.Remove("GET", "/b"); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	wg.Wait()
+}
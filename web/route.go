@@ -0,0 +1,57 @@
+package web
+
+import (
+	"log"
+)
+
+// Route is a handle to a route that has been added to a Mux. It is returned by
+// the HTTP method functions (Get, Post, etc.), and can be used to attach
+// additional properties to the route it refers to.
+//
+// As with the functions which add routes, it is illegal to call any of Route's
+// methods concurrently with active requests.
+type Route struct {
+	rt *router
+	id int
+}
+
+// Name gives the route a name, which can later be used to generate URLs that
+// the route would match (see Mux.URL). Route names must be unique within a Mux.
+func (r *Route) Name(name string) *Route {
+	r.rt.modify(r.id, func(rte *route) {
+		if rte.name == name {
+			return
+		}
+		if _, ok := r.rt.names[name]; ok {
+			log.Fatalf("web: duplicate route name %q", name)
+		}
+		if rte.name != "" {
+			delete(r.rt.names, rte.name)
+		}
+		if r.rt.names == nil {
+			r.rt.names = make(map[string]Pattern)
+		}
+		r.rt.names[name] = rte.pattern
+		rte.name = name
+	})
+	return r
+}
+
+// Meta attaches a key-value pair of metadata to the route, for instance the
+// authorization scopes the route requires. When the route matches a request,
+// its metadata is available to middleware and handlers as the Meta property of
+// the Match (see Mux.Router and GetMatch). Setting a key which has already been
+// set replaces its value.
+func (r *Route) Meta(key, value interface{}) *Route {
+	r.rt.modify(r.id, func(rte *route) {
+		// Matches share the route's map, so copy it instead of
+		// modifying it in place.
+		meta := make(map[interface{}]interface{}, len(rte.meta)+1)
+		for k, v := range rte.meta {
+			meta[k] = v
+		}
+		meta[key] = value
+		rte.meta = meta
+	})
+	return r
+}
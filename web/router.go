@@ -1,13 +1,15 @@
 package web
 
 import (
+	"fmt"
+	"log"
 	"net/http"
 	"sort"
 	"strings"
 	"sync"
 )
 
-type method int
+type method uint64
 
 const (
 	mCONNECT method = 1 << iota
@@ -20,15 +22,17 @@ const (
 	mPUT
 	mTRACE
 	// We only natively support the methods above, but we pass through other
-	// methods. This constant pretty much only exists for the sake of mALL.
+	// methods. Extension methods (see Mux.Method) are allocated the bits
+	// above this one as they are registered, and any other method is
+	// lumped in here. This constant pretty much only exists for the sake of
+	// mALL.
 	mIDK
 
-	mALL method = mCONNECT | mDELETE | mGET | mHEAD | mOPTIONS | mPATCH |
-		mPOST | mPUT | mTRACE | mIDK
+	mALL method = ^method(0)
 )
 
-// The key used to communicate to the NotFound handler what methods would have
-// been allowed if they'd been provided.
+// The key used to communicate to the NotFound and MethodNotAllowed handlers what
+// methods would have been allowed if they'd been provided.
 const ValidMethodsKey = "goji.web.ValidMethods"
 
 var validMethodsMap = map[string]method{
@@ -44,32 +48,69 @@ var validMethodsMap = map[string]method{
 }
 
 type route struct {
-	prefix  string
-	method  method
-	pattern Pattern
-	handler Handler
+	prefix     string
+	method     method
+	pattern    Pattern
+	handler    Handler
+	predicates []Predicate
+	id         int
+	name       string
+	meta       map[interface{}]interface{}
+	// group is the middleware of the Group the route was added through,
+	// which is reapplied if the route's handler is replaced.
+	group []MiddlewareType
 }
 
 type router struct {
-	lock     sync.Mutex
-	routes   []route
-	notFound Handler
-	machine  *routeMachine
+	lock             sync.Mutex
+	routes           []route
+	notFound         Handler
+	methodNotAllowed Handler
+	machine          *routeMachine
+	radix            bool
+	nextID           int
+	names            map[string]Pattern
+	// methods maps method names to their bits. It is validMethodsMap until
+	// an extension method is registered, and is replaced (never mutated)
+	// each time one is.
+	methods      map[string]method
+	pathPolicy   PathPolicy
+	escapedPaths bool
+	attachC      bool
+	fold         bool
 }
 
-func httpMethod(mname string) method {
-	if method, ok := validMethodsMap[mname]; ok {
+func httpMethod(methods map[string]method, mname string) method {
+	if method, ok := methods[mname]; ok {
 		return method
 	}
 	return mIDK
 }
 
+// methodNames returns the sorted list of names of the methods in the given set.
+func methodNames(methods method, names map[string]method) []string {
+	var methodsList = make([]string, 0)
+	for mname, meth := range names {
+		if methods&meth != 0 {
+			methodsList = append(methodsList, mname)
+		}
+	}
+	sort.Strings(methodsList)
+	return methodsList
+}
+
 func (rt *router) compile() *routeMachine {
 	rt.lock.Lock()
 	defer rt.lock.Unlock()
 	sm := routeMachine{
-		sm:     compile(rt.routes),
-		routes: rt.routes,
+		routes:  rt.routes,
+		methods: rt.methods,
+		fold:    rt.fold,
+	}
+	if rt.radix {
+		sm.tree = buildRadixTree(rt.routes, rt.fold)
+	} else {
+		sm.sm = compile(rt.routes)
 	}
 	rt.setMachine(&sm)
 	return &sm
@@ -80,35 +121,71 @@ func (rt *router) getMatch(c *C, w http.ResponseWriter, r *http.Request) Match {
 	if rm == nil {
 		rm = rt.compile()
 	}
+	if !rt.escapedPaths {
+		return rt.match(rm, c, w, r)
+	}
 
-	methods, route := rm.route(c, w, r)
-	if route != nil {
-		return Match{
-			Pattern: route.pattern,
-			Handler: route.handler,
+	ec, er := escapedRequest(c, r)
+	match := rt.match(rm, ec, w, er)
+	mergeEscaped(c, ec)
+	return match
+}
+
+func (rt *router) match(rm *routeMachine, c *C, w http.ResponseWriter, r *http.Request) Match {
+	methods, status, route := rm.route(c, w, r)
+	if route == nil && methods == 0 && rt.pathPolicy != PathStrict {
+		var match Match
+		var ok bool
+		match, methods, status, ok = rt.canonicalMatch(rm, c, w, r)
+		if ok {
+			return match
 		}
 	}
+	if route != nil {
+		return route.match()
+	}
 
 	if methods == 0 {
 		return Match{Handler: rt.notFound}
 	}
 
-	var methodsList = make([]string, 0)
-	for mname, meth := range validMethodsMap {
-		if methods&meth != 0 {
-			methodsList = append(methodsList, mname)
-		}
+	if c.Env == nil {
+		c.Env = make(map[interface{}]interface{}, 2)
 	}
-	sort.Strings(methodsList)
+	setEnvValidMethods(c, methodNames(methods, rm.methods))
+	if status != 0 {
+		setEnvPredicateStatus(c, status)
+		return Match{Handler: rt.notFound}
+	}
+	if methods&httpMethod(rm.methods, r.Method) != 0 {
+		return Match{Handler: rt.notFound}
+	}
+	return Match{Handler: rt.methodNotAllowed}
+}
 
-	if c.Env == nil {
-		c.Env = map[interface{}]interface{}{
-			ValidMethodsKey: methodsList,
-		}
-	} else {
-		c.Env[ValidMethodsKey] = methodsList
+func (r *route) match() Match {
+	return Match{
+		Pattern: r.pattern,
+		Handler: r.handler,
+		Name:    r.name,
+		Meta:    r.meta,
 	}
-	return Match{Handler: rt.notFound}
+}
+
+func defaultNotFound(c C, w http.ResponseWriter, r *http.Request) {
+	if status, ok := envPredicateStatus(c); ok {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func defaultMethodNotAllowed(c C, w http.ResponseWriter, r *http.Request) {
+	if methods, ok := envValidMethods(c); ok {
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+	}
+	status := http.StatusMethodNotAllowed
+	http.Error(w, http.StatusText(status), status)
 }
 
 func (rt *router) route(c *C, w http.ResponseWriter, r *http.Request) {
@@ -116,21 +193,41 @@ func (rt *router) route(c *C, w http.ResponseWriter, r *http.Request) {
 	if match.Handler == nil {
 		match = rt.getMatch(c, w, r)
 	}
+	if rt.attachC {
+		r = withC(r, *c)
+	}
 	match.Handler.ServeHTTPC(*c, w, r)
 }
 
-func (rt *router) handleUntyped(p PatternType, m method, h HandlerType) {
-	rt.handle(ParsePattern(p), m, parseHandler(h))
+func (rt *router) handleUntyped(p PatternType, m method, h HandlerType, middleware []MiddlewareType) *Route {
+	return rt.handle(ParsePattern(p), m, parseHandler(h), nil, middleware)
 }
 
-func (rt *router) handle(p Pattern, m method, h Handler) {
+// groupMiddleware returns the middleware stack of a route: the Group's
+// middleware runs before (outside) the route's own.
+func groupMiddleware(group, middleware []MiddlewareType) []MiddlewareType {
+	if len(group) == 0 {
+		return middleware
+	}
+	mw := make([]MiddlewareType, 0, len(group)+len(middleware))
+	mw = append(mw, group...)
+	return append(mw, middleware...)
+}
+
+func (rt *router) handle(p Pattern, m method, h Handler, group, middleware []MiddlewareType) *Route {
+	h = withMiddleware(h, groupMiddleware(group, middleware))
+
 	rt.lock.Lock()
 	defer rt.lock.Unlock()
 
+	if rt.fold {
+		p = foldPattern(p)
+	}
+
 	// Calculate the sorted insertion point, because there's no reason to do
 	// swapping hijinks if we're already making a copy. We need to use
 	// bubble sort because we can only compare adjacent elements.
-	pp := p.Prefix()
+	pp := rt.routePrefix(p)
 	var i int
 	for i = len(rt.routes); i > 0; i-- {
 		rip := rt.routes[i-1].prefix
@@ -141,14 +238,133 @@ func (rt *router) handle(p Pattern, m method, h Handler) {
 
 	newRoutes := make([]route, len(rt.routes)+1)
 	copy(newRoutes, rt.routes[:i])
+	rt.nextID++
 	newRoutes[i] = route{
 		prefix:  pp,
 		method:  m,
 		pattern: p,
 		handler: h,
+		id:      rt.nextID,
+		group:   group,
 	}
 	copy(newRoutes[i+1:], rt.routes[i:])
 
 	rt.setMachine(nil)
 	rt.routes = newRoutes
+	return &Route{rt: rt, id: rt.nextID}
+}
+
+// modify applies the given function to a copy of the route with the given ID.
+// As with adding routes, the route list is copied so that requests routed by an
+// existing machine are unaffected.
+func (rt *router) modify(id int, fn func(*route)) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	newRoutes := make([]route, len(rt.routes))
+	copy(newRoutes, rt.routes)
+	for i := range newRoutes {
+		if newRoutes[i].id == id {
+			fn(&newRoutes[i])
+			break
+		}
+	}
+
+	rt.setMachine(nil)
+	rt.routes = newRoutes
+}
+
+func (rt *router) setRadix(enabled bool) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	rt.radix = enabled
+	rt.setMachine(nil)
+}
+
+// method returns the method bit for the method with the given name, allocating
+// a new one if it is an extension method we haven't seen before.
+func (rt *router) method(mname string) method {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	if mname == "" {
+		log.Fatalf("web: HTTP method must not be empty")
+	}
+	if m, ok := rt.methods[mname]; ok {
+		return m
+	}
+
+	n := len(rt.methods) - len(validMethodsMap)
+	m := mIDK << uint(n+1)
+	if m == 0 {
+		log.Fatalf("web: too many extension HTTP methods (adding %q)", mname)
+	}
+	methods := make(map[string]method, len(rt.methods)+1)
+	for k, v := range rt.methods {
+		methods[k] = v
+	}
+	methods[mname] = m
+
+	rt.setMachine(nil)
+	rt.methods = methods
+	return m
+}
+
+// find returns the index of the first route (in the order routes were added)
+// which responds to the given method and has a pattern identical to the given
+// one, or -1 if there is none. The caller must hold the lock.
+func (rt *router) find(m method, p Pattern) int {
+	if rt.fold {
+		p = foldPattern(p)
+	}
+	found := -1
+	for i, r := range rt.routes {
+		if r.method&m == 0 || !identicalPattern(r.pattern, p) {
+			continue
+		}
+		if found == -1 || r.id < rt.routes[found].id {
+			found = i
+		}
+	}
+	return found
+}
+
+func (rt *router) remove(mname string, p Pattern) error {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	i := rt.find(httpMethod(rt.methods, mname), p)
+	if i == -1 {
+		return fmt.Errorf("web: no %s route with pattern %v", mname, p)
+	}
+	if name := rt.routes[i].name; name != "" {
+		delete(rt.names, name)
+	}
+
+	newRoutes := make([]route, len(rt.routes)-1)
+	copy(newRoutes, rt.routes[:i])
+	copy(newRoutes[i:], rt.routes[i+1:])
+
+	rt.setMachine(nil)
+	rt.routes = newRoutes
+	return nil
+}
+
+func (rt *router) replace(mname string, p Pattern, h Handler, middleware []MiddlewareType) error {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	i := rt.find(httpMethod(rt.methods, mname), p)
+	if i == -1 {
+		return fmt.Errorf("web: no %s route with pattern %v", mname, p)
+	}
+
+	newRoutes := make([]route, len(rt.routes))
+	copy(newRoutes, rt.routes)
+	newRoutes[i].handler = withMiddleware(h,
+		groupMiddleware(newRoutes[i].group, middleware))
+
+	rt.setMachine(nil)
+	rt.routes = newRoutes
+	return nil
 }
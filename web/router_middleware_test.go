@@ -33,3 +33,46 @@ func TestRouterMiddleware(t *testing.T) {
 		t.Errorf("Routing was not frozen! %s", v)
 	}
 }
+
+func TestRouterMiddlewareMeta(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	ch := make(chan Match, 1)
+	m.Use(m.Router)
+	m.Use(func(c *C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ch <- GetMatch(*c)
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	})
+	m.Get("/admin", http.NotFound).Name("admin").
+		Meta("scopes", []string{"admin"}).
+		Meta("deprecated", false).
+		Meta("deprecated", true)
+	m.Get("/public", http.NotFound)
+
+	r, _ := http.NewRequest("GET", "/admin", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	match := <-ch
+	if match.Name != "admin" {
+		t.Errorf("Expected name admin, got %q", match.Name)
+	}
+	if scopes, ok := match.Meta["scopes"].([]string); !ok ||
+		len(scopes) != 1 || scopes[0] != "admin" {
+		t.Errorf("Unexpected scopes %v", match.Meta["scopes"])
+	}
+	if match.Meta["deprecated"] != true {
+		t.Errorf("Expected deprecated to be true, got %v",
+			match.Meta["deprecated"])
+	}
+
+	r, _ = http.NewRequest("GET", "/public", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	match = <-ch
+	if match.Name != "" || match.Meta != nil {
+		t.Errorf("Expected no name or metadata, got %q %v", match.Name,
+			match.Meta)
+	}
+}
@@ -274,7 +274,7 @@ func TestValidMethods(t *testing.T) {
 	m := New()
 	ch := make(chan []string, 1)
 
-	m.NotFound(func(c C, w http.ResponseWriter, r *http.Request) {
+	validMethods := func(c C, w http.ResponseWriter, r *http.Request) {
 		if c.Env == nil {
 			ch <- []string{}
 			return
@@ -285,7 +285,9 @@ func TestValidMethods(t *testing.T) {
 			return
 		}
 		ch <- methods.([]string)
-	})
+	}
+	m.NotFound(validMethods)
+	m.MethodNotAllowed(validMethods)
 
 	m.Get("/hello/carl", http.NotFound)
 	m.Post("/hello/carl", http.NotFound)
@@ -324,3 +326,140 @@ func TestValidMethods(t *testing.T) {
 		}
 	}
 }
+
+func TestConstraintFallthrough(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 1)
+
+	m.Get("/users/:id{int}", func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- "id " + c.URLParams["id"]
+	})
+	m.Get("/users/:name", func(c C, w http.ResponseWriter, r *http.Request) {
+		ch <- "name " + c.URLParams["name"]
+	})
+
+	for path, expected := range map[string]string{
+		"/users/42":   "id 42",
+		"/users/carl": "name carl",
+	} {
+		r, _ := http.NewRequest("GET", path, nil)
+		m.ServeHTTP(httptest.NewRecorder(), r)
+		if actual := <-ch; actual != expected {
+			t.Errorf("For %q, expected %q, got %q", path, expected,
+				actual)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.Get("/hello", http.NotFound)
+	m.Put("/hello", http.NotFound)
+
+	r, _ := http.NewRequest("POST", "/hello", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", w.Code)
+	}
+	if allow := w.HeaderMap.Get("Allow"); allow != "GET, HEAD, PUT" {
+		t.Errorf(`Expected Allow of "GET, HEAD, PUT", got %q`, allow)
+	}
+
+	r, _ = http.NewRequest("POST", "/goodbye", nil)
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+
+	m.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "I'm a teapot!", http.StatusTeapot)
+	})
+	r, _ = http.NewRequest("POST", "/hello", nil)
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected a teapot, got %d", w.Code)
+	}
+}
+
+func TestExtensionMethods(t *testing.T) {
+	t.Parallel()
+	for _, radix := range []bool{false, true} {
+		m := New()
+		m.RadixRouting(radix)
+		ch := make(chan string, 1)
+		m.Method("PROPFIND", "/dav/:file", chHandler(ch, "propfind"))
+		m.Method("MKCOL", "/dav/:file", chHandler(ch, "mkcol"))
+		m.Method("GET", "/dav/:file", chHandler(ch, "get"))
+		m.Handle("/any", chHandler(ch, "any"))
+
+		tests := []struct {
+			method, path, expected string
+		}{
+			{"PROPFIND", "/dav/a", "propfind"},
+			{"MKCOL", "/dav/a", "mkcol"},
+			{"GET", "/dav/a", "get"},
+			{"PROPFIND", "/any", "any"},
+			{"BOGUS", "/any", "any"},
+		}
+		for _, test := range tests {
+			r, _ := http.NewRequest(test.method, test.path, nil)
+			m.ServeHTTP(httptest.NewRecorder(), r)
+			if actual := <-ch; actual != test.expected {
+				t.Errorf("%s %s: expected %q, got %q", test.method,
+					test.path, test.expected, actual)
+			}
+		}
+
+		for _, meth := range []string{"LOCK", "HEAD"} {
+			r, _ := http.NewRequest(meth, "/dav/a", nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, r)
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("%s: expected 405, got %d", meth, w.Code)
+			}
+			allow := w.HeaderMap.Get("Allow")
+			if allow != "GET, MKCOL, PROPFIND" {
+				t.Errorf(`%s: expected Allow of "GET, MKCOL, PROPFIND", got %q`,
+					meth, allow)
+			}
+		}
+	}
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	t.Parallel()
+	m := New()
+	ch := make(chan string, 4)
+	m.Use(chanWare(ch, "mux"))
+
+	requireName := func(c *C, h http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ch <- "user " + c.URLParams["name"]
+			h.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+	h := chHandler(ch, "handler")
+	m.Get("/users/:name", h, requireName, chanWare(ch, "route"))
+	m.Get("/public", chHandler(ch, "public"))
+
+	for i := 0; i < 2; i++ {
+		r, _ := http.NewRequest("GET", "/users/carl", nil)
+		m.ServeHTTP(httptest.NewRecorder(), r)
+		assertOrder(t, ch, "mux", "user carl", "route", "handler")
+	}
+
+	r, _ := http.NewRequest("GET", "/public", nil)
+	m.ServeHTTP(httptest.NewRecorder(), r)
+	assertOrder(t, ch, "mux", "public")
+
+	if _, ok := m.Routes()[0].Handler.(http.HandlerFunc); !ok {
+		t.Errorf("Expected the route's raw handler, got %T",
+			m.Routes()[0].Handler)
+	}
+}
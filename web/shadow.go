@@ -0,0 +1,258 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ShadowedRoute describes a route that can never be reached for some or all of
+// its HTTP methods, because an earlier route will always match first.
+type ShadowedRoute struct {
+	// Route is the unreachable route.
+	Route RouteInfo
+	// By is the earlier route which prevents Route from being reached.
+	By RouteInfo
+	// Methods is the list of methods for which Route is unreachable, or
+	// nil if it is unreachable for every method.
+	Methods []string
+	// Duplicate is true if the two routes were added with identical
+	// patterns.
+	Duplicate bool
+}
+
+func (s ShadowedRoute) String() string {
+	kind := "shadowed by"
+	if s.Duplicate {
+		kind = "duplicates"
+	}
+	methods := "all methods"
+	if s.Methods != nil {
+		methods = strings.Join(s.Methods, ", ")
+	}
+	return fmt.Sprintf("%v (%s) %s earlier route %v", s.Route.Pattern,
+		methods, kind, s.By.Pattern)
+}
+
+// ShadowError is the error returned by Mux.CompileStrict when the Mux contains
+// unreachable routes.
+type ShadowError []ShadowedRoute
+
+func (e ShadowError) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "web: %d unreachable route(s):", len(e))
+	for _, s := range e {
+		buf.WriteString("\n\t")
+		buf.WriteString(s.String())
+	}
+	return buf.String()
+}
+
+/*
+findShadowed examines the given list of routes (which must be in the order in
+which they were added) for routes which can never be matched. This is, in
+general, impossible: we can't reason about the behavior of arbitrary Patterns.
+We can, however, do a pretty good job for string patterns, which are by far the
+most common, and we can detect exact duplicates of any of the built-in pattern
+types.
+
+A route is reported if the routes before it which are guaranteed to match every
+path it matches also cover all of its methods, or if an earlier route with an
+overlapping set of methods has an identical pattern. Earlier routes with
+predicates (see Route.Where) are ignored.
+*/
+func findShadowed(routes []route, names map[string]method) ShadowError {
+	var shadowed ShadowError
+	for j, b := range routes {
+		var covered method
+		var by, dup *route
+		for i := range routes[:j] {
+			a := &routes[i]
+			overlap := a.method & b.method
+			// Routes with predicates may decline any request, so
+			// they never make a later route unreachable.
+			if overlap == 0 || len(a.predicates) != 0 {
+				continue
+			}
+			same := samePattern(a.pattern, b.pattern)
+			if same && dup == nil {
+				dup = a
+			}
+			if same || coversPattern(a.pattern, b.pattern) {
+				covered |= overlap
+				if covered == b.method {
+					by = a
+					break
+				}
+			}
+		}
+
+		if by != nil {
+			shadowed = append(shadowed, ShadowedRoute{
+				Route:     b.info(names),
+				By:        by.info(names),
+				Duplicate: samePattern(by.pattern, b.pattern),
+			})
+		} else if dup != nil {
+			shadowed = append(shadowed, ShadowedRoute{
+				Route:     b.info(names),
+				By:        dup.info(names),
+				Methods:   methodNames(dup.method&b.method, names),
+				Duplicate: true,
+			})
+		}
+	}
+	return shadowed
+}
+
+func (rt *router) findShadowed() ShadowError {
+	rt.lock.Lock()
+	routes := make([]route, len(rt.routes))
+	copy(routes, rt.routes)
+	names := rt.methods
+	rt.lock.Unlock()
+
+	sort.Sort(routesByID(routes))
+	return findShadowed(routes, names)
+}
+
+func samePattern(a, b Pattern) bool {
+	switch av := a.(type) {
+	case stringPattern:
+		bv, ok := b.(stringPattern)
+		return ok && av.raw == bv.raw && av.fold == bv.fold
+	case optionalPattern:
+		bv, ok := b.(optionalPattern)
+		return ok && av.raw == bv.raw && av.alts[0].fold == bv.alts[0].fold
+	case regexpPattern:
+		bv, ok := b.(regexpPattern)
+		return ok && av.re.String() == bv.re.String()
+	}
+	return false
+}
+
+// identicalPattern returns true if a and b are known to be the same pattern. In
+// addition to the cases samePattern understands, Patterns which are comparable
+// and equal are identical.
+func identicalPattern(a, b Pattern) bool {
+	if samePattern(a, b) {
+		return true
+	}
+	if ap, ok := a.(prefixPattern); ok {
+		bp, ok := b.(prefixPattern)
+		return ok && ap.prefix == bp.prefix &&
+			identicalPattern(ap.pat, bp.pat)
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	return ta == tb && ta != nil && ta.Comparable() && a == b
+}
+
+// A patternSegment is a single "/"-delimited segment of a string pattern.
+type patternSegment struct {
+	// The segment's text, with each named parameter replaced by
+	// paramSegment
+	text        string
+	constraints []*regexp.Regexp
+}
+
+func (p patternSegment) literal() bool {
+	return len(p.constraints) == 0
+}
+
+// param returns true if the segment consists of exactly one named parameter.
+func (p patternSegment) param() bool {
+	return p.text == paramSegment
+}
+
+func (p patternSegment) equal(o patternSegment) bool {
+	if p.text != o.text || len(p.constraints) != len(o.constraints) {
+		return false
+	}
+	for i, c := range p.constraints {
+		oc := o.constraints[i]
+		if (c == nil) != (oc == nil) ||
+			(c != nil && c.String() != oc.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+// covers returns true if every string matched by o is also matched by p.
+func (p patternSegment) covers(o patternSegment) bool {
+	switch {
+	case p.equal(o):
+		return true
+	case p.literal():
+		return false
+	case p.param() && p.constraints[0] == nil:
+		return o.text != ""
+	case p.param() && o.literal():
+		return p.constraints[0].MatchString(o.text)
+	}
+	return false
+}
+
+func stringSegments(s stringPattern) ([]patternSegment, bool) {
+	src := strings.Join(s.literals, paramSegment)
+	if !strings.HasPrefix(src, "/") || s.multiSegment() {
+		return nil, false
+	}
+	texts := strings.Split(src[1:], "/")
+	if s.wildcard {
+		texts = texts[:len(texts)-1]
+	}
+	segs := make([]patternSegment, len(texts))
+	n := 0
+	for i, text := range texts {
+		k := strings.Count(text, paramSegment)
+		segs[i] = patternSegment{
+			text:        text,
+			constraints: s.constraints[n : n+k],
+		}
+		n += k
+	}
+	return segs, true
+}
+
+// coversPattern returns true if a is guaranteed to match every request b does.
+func coversPattern(a, b Pattern) bool {
+	as, ok := a.(stringPattern)
+	if !ok {
+		return false
+	}
+	bs, ok := b.(stringPattern)
+	if !ok || (bs.fold && !as.fold) {
+		return false
+	}
+	asegs, ok := stringSegments(as)
+	if !ok {
+		return false
+	}
+	bsegs, ok := stringSegments(bs)
+	if !ok {
+		return false
+	}
+
+	if as.wildcard {
+		// "/a/*" matches everything beginning with "/a/", so b must
+		// have at least one more segment than a (or be a wildcard
+		// itself)
+		if len(bsegs) < len(asegs) ||
+			(len(bsegs) == len(asegs) && !bs.wildcard) {
+			return false
+		}
+	} else if bs.wildcard || len(asegs) != len(bsegs) {
+		return false
+	}
+
+	for i, aseg := range asegs {
+		if !aseg.covers(bsegs[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package web
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+var shadowTests = []struct {
+	a, b     string
+	shadowed bool
+}{
+	{"/users/:id", "/users/new", true},
+	{"/users/new", "/users/:id", false},
+	{"/users/:id", "/users/:name", true},
+	{"/users/:id{int}", "/users/42", true},
+	{"/users/:id{int}", "/users/new", false},
+	{"/users/:id{int}", "/users/:id", false},
+	{"/users/:id", "/users/:id{int}", true},
+	{"/users/:id", "/users/", false},
+	{"/users/:id", "/users/:id/edit", false},
+	{"/users/*", "/users/:id/edit", true},
+	{"/users/*", "/users/", true},
+	{"/users/*", "/users", false},
+	{"/users/*", "/users/:id/*", true},
+	{"/users/:id/*", "/users/*", false},
+	{"/files/:name.:ext", "/files/:name.:ext", true},
+	{"/files/:name.:ext", "/files/cat.gif", false},
+	{"/hello", "/hello", true},
+}
+
+func TestCompileStrict(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range shadowTests {
+		m := New()
+		m.Get(test.a, http.NotFound)
+		m.Get(test.b, http.NotFound)
+		err := m.CompileStrict()
+		if test.shadowed && err == nil {
+			t.Errorf("Expected %q to shadow %q", test.a, test.b)
+		} else if !test.shadowed && err != nil {
+			t.Errorf("Expected %q not to shadow %q: %v", test.a,
+				test.b, err)
+		}
+	}
+}
+
+func TestCompileStrictCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	m.Get("/about", http.NotFound)
+	m.Get(CaseInsensitive("/about"), http.NotFound)
+	if err := m.CompileStrict(); err != nil {
+		t.Errorf("Case-insensitive route was reported: %v", err)
+	}
+
+	m = New()
+	m.Get(CaseInsensitive("/about"), http.NotFound)
+	m.Get("/about", http.NotFound)
+	if err := m.CompileStrict(); err == nil {
+		t.Error("Expected case-insensitive route to shadow /about")
+	}
+}
+
+func TestCompileStrictMethods(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	m.Get("/users/:id", http.NotFound)
+	m.Post("/users/new", http.NotFound)
+	if err := m.CompileStrict(); err != nil {
+		t.Errorf("Routes with disjoint methods were reported: %v", err)
+	}
+
+	// Two routes together can shadow a third
+	m.Put("/users/:id", http.NotFound)
+	m.Handle("/users/*", http.NotFound)
+	m.Get("/users/new", http.NotFound)
+	m.Put("/users/new", http.NotFound)
+	err := m.CompileStrict()
+	se, ok := err.(ShadowError)
+	if !ok || len(se) != 2 {
+		t.Fatalf("Expected two shadowed routes, got %v", err)
+	}
+	if se[0].Route.Methods[0] != "GET" || se[0].By.Pattern != "/users/:id" {
+		t.Errorf("Unexpected shadowed route %v", se[0])
+	}
+	if se[1].Route.Methods[0] != "PUT" || se[1].By.Pattern != "/users/:id" {
+		t.Errorf("Unexpected shadowed route %v", se[1])
+	}
+}
+
+func TestCompileStrictDuplicates(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	re := `^/greets/(?P<id>\d+)$`
+	m.Get(regexp.MustCompile(re), http.NotFound)
+	m.Get(regexp.MustCompile(re), http.NotFound)
+	m.Get("/greets", http.NotFound)
+	m.Handle("/greets", http.NotFound)
+	err := m.CompileStrict()
+	se, ok := err.(ShadowError)
+	if !ok || len(se) != 2 {
+		t.Fatalf("Expected two duplicate routes, got %v", err)
+	}
+	if !se[0].Duplicate || se[0].Methods != nil {
+		t.Errorf("Expected a full duplicate, got %v", se[0])
+	}
+	if !se[1].Duplicate || len(se[1].Methods) != 2 {
+		t.Errorf("Expected a partial duplicate, got %v", se[1])
+	}
+}
+
+func TestCompileStrictPredicates(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	m.Get("/report", http.NotFound).Where(Query("format", "csv"))
+	m.Get("/report", http.NotFound)
+	m.Get("/users/:id", http.NotFound).Where(Header("X-Admin", "1"))
+	m.Get("/users/new", http.NotFound)
+	if err := m.CompileStrict(); err != nil {
+		t.Errorf("Expected fallback routes to be reachable, got %v", err)
+	}
+
+	m.Get("/report", http.NotFound)
+	if err := m.CompileStrict(); err == nil {
+		t.Error("Expected duplicate of fallback route to be reported")
+	}
+}
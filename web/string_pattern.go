@@ -1,7 +1,9 @@
 package web
 
 import (
+	"bytes"
 	"fmt"
+	"log"
 	"net/http"
 	"regexp"
 	"strings"
@@ -9,16 +11,29 @@ import (
 
 // stringPattern is a struct describing
 type stringPattern struct {
-	raw      string
-	pats     []string
-	breaks   []byte
+	raw         string
+	pats        []string
+	breaks      []byte
+	constraints []*regexp.Regexp
+	// multi records which parameters are catch-alls (e.g., "*path"),
+	// which may span several path segments.
+	multi    []bool
 	literals []string
 	wildcard bool
+	// fold is set if literals should be compared case-insensitively (see
+	// CaseInsensitive).
+	fold bool
 }
 
 func (s stringPattern) Prefix() string {
+	if s.fold {
+		return foldLiteralPrefix(s.literals[0])
+	}
 	return s.literals[0]
 }
+func (s stringPattern) foldPrefix() string {
+	return lowerASCII(s.literals[0])
+}
 func (s stringPattern) Match(r *http.Request, c *C) bool {
 	return s.match(r, c, true)
 }
@@ -26,7 +41,6 @@ func (s stringPattern) Run(r *http.Request, c *C) {
 	s.match(r, c, false)
 }
 func (s stringPattern) match(r *http.Request, c *C, dryrun bool) bool {
-	path := r.URL.Path
 	var matches map[string]string
 	if !dryrun {
 		if s.wildcard {
@@ -35,13 +49,53 @@ func (s stringPattern) match(r *http.Request, c *C, dryrun bool) bool {
 			matches = make(map[string]string, len(s.pats))
 		}
 	}
-	for i, pat := range s.pats {
+	if !s.matchFrom(0, r.URL.Path, matches) {
+		return false
+	}
+
+	if c == nil || dryrun {
+		return true
+	}
+
+	if c.URLParams == nil {
+		c.URLParams = matches
+	} else {
+		for k, v := range matches {
+			c.URLParams[k] = v
+		}
+	}
+	return true
+}
+
+// matchFrom matches the given remainder of a path against the pattern, starting
+// with its i'th parameter, and binds parameters into matches if it is non-nil.
+func (s stringPattern) matchFrom(i int, path string, matches map[string]string) bool {
+	for ; i < len(s.pats); i++ {
+		pat := s.pats[i]
 		sli := s.literals[i]
-		if !strings.HasPrefix(path, sli) {
+		if !s.hasPrefix(path, sli) {
 			return false
 		}
 		path = path[len(sli):]
 
+		if s.multi[i] {
+			// Catch-alls match as much of the path as they can,
+			// giving ground until the rest of the pattern matches.
+			cr := s.constraints[i]
+			for m := len(path); m > 0; m-- {
+				if cr != nil && !cr.MatchString(path[:m]) {
+					continue
+				}
+				if matches != nil {
+					matches[pat] = path[:m]
+				}
+				if s.matchFrom(i+1, path[m:], matches) {
+					return true
+				}
+			}
+			return false
+		}
+
 		m := 0
 		bc := s.breaks[i]
 		for ; m < len(path); m++ {
@@ -54,7 +108,10 @@ func (s stringPattern) match(r *http.Request, c *C, dryrun bool) bool {
 			// "/:foo" would match the path "/"
 			return false
 		}
-		if !dryrun {
+		if cr := s.constraints[i]; cr != nil && !cr.MatchString(path[:m]) {
+			return false
+		}
+		if matches != nil {
 			matches[pat] = path[:m]
 		}
 		path = path[m:]
@@ -62,28 +119,72 @@ func (s stringPattern) match(r *http.Request, c *C, dryrun bool) bool {
 	// There's exactly one more literal than pat.
 	tail := s.literals[len(s.pats)]
 	if s.wildcard {
-		if !strings.HasPrefix(path, tail) {
+		if !s.hasPrefix(path, tail) {
 			return false
 		}
-		if !dryrun {
+		if matches != nil {
 			matches["*"] = path[len(tail)-1:]
 		}
-	} else if path != tail {
+	} else if !s.hasPrefix(path, tail) || len(path) != len(tail) {
 		return false
 	}
+	return true
+}
 
-	if c == nil || dryrun {
-		return true
+// hasPrefix returns true if path begins with the given literal.
+func (s stringPattern) hasPrefix(path, literal string) bool {
+	if s.fold {
+		return len(path) >= len(literal) &&
+			equalFoldASCII(path[:len(literal)], literal)
 	}
+	return strings.HasPrefix(path, literal)
+}
 
-	if c.URLParams == nil {
-		c.URLParams = matches
-	} else {
-		for k, v := range matches {
-			c.URLParams[k] = v
+// multiSegment returns true if any of the pattern's parameters is a catch-all.
+func (s stringPattern) multiSegment() bool {
+	for _, multi := range s.multi {
+		if multi {
+			return true
 		}
 	}
-	return true
+	return false
+}
+
+func (s stringPattern) buildURL(params map[string]string) (string, error) {
+	var buf bytes.Buffer
+	for i, pat := range s.pats {
+		buf.WriteString(s.literals[i])
+		v := params[pat]
+		if v == "" {
+			return "", fmt.Errorf("missing parameter %q", pat)
+		}
+		if !s.multi[i] && (strings.IndexByte(v, '/') != -1 ||
+			strings.IndexByte(v, s.breaks[i]) != -1) {
+			return "", fmt.Errorf("parameter %q may not contain %q",
+				pat, s.breaks[i])
+		}
+		if cr := s.constraints[i]; cr != nil && !cr.MatchString(v) {
+			return "", fmt.Errorf("parameter %q (%q) does not "+
+				"satisfy constraint %v", pat, v, cr)
+		}
+		buf.WriteString(v)
+	}
+
+	tail := s.literals[len(s.pats)]
+	if s.wildcard {
+		buf.WriteString(tail[:len(tail)-1])
+		v, ok := params["*"]
+		if !ok {
+			v = "/"
+		} else if !strings.HasPrefix(v, "/") {
+			return "", fmt.Errorf(`parameter "*" (%q) must begin `+
+				`with "/"`, v)
+		}
+		buf.WriteString(v)
+	} else {
+		buf.WriteString(tail)
+	}
+	return escapePath(buf.String()), nil
 }
 
 func (s stringPattern) String() string {
@@ -100,7 +201,51 @@ func (s stringPattern) Raw() string {
 // and "," were chosen because Section 3.3 of RFC 3986 suggests their use.
 const bc = "/.;,"
 
-var patternRe = regexp.MustCompile(`[` + bc + `]:([^` + bc + `]+)`)
+var patternRe = regexp.MustCompile(`[` + bc + `](?::([^` + bc + `{]+)|\*([^` +
+	bc + `{]+))`)
+
+// Named constraints which may be used in place of a regular expression, as in
+// "/users/:id{int}".
+var namedConstraints = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[A-Za-z]+`,
+	"alnum": `[A-Za-z0-9]+`,
+	"hex":   `[0-9A-Fa-f]+`,
+	"uuid": `[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-` +
+		`[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}`,
+}
+
+// closingBrace returns the index of the brace which closes the one at s[i], or
+// -1 if it is never closed. Braces may nest, since constraints are allowed to
+// contain regular expression repetitions like "{2,3}".
+func closingBrace(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseConstraint(raw, constraint string) *regexp.Regexp {
+	if named, ok := namedConstraints[constraint]; ok {
+		constraint = named
+	}
+	re, err := regexp.Compile(`^(?:` + constraint + `)$`)
+	if err != nil {
+		log.Fatalf("web: invalid constraint %q in pattern %q: %v",
+			constraint, raw, err)
+	}
+	return re
+}
 
 func parseStringPattern(s string) stringPattern {
 	raw := s
@@ -110,28 +255,54 @@ func parseStringPattern(s string) stringPattern {
 		wildcard = true
 	}
 
-	matches := patternRe.FindAllStringSubmatchIndex(s, -1)
-	pats := make([]string, len(matches))
-	breaks := make([]byte, len(matches))
-	literals := make([]string, len(matches)+1)
+	var pats []string
+	var breaks []byte
+	var constraints []*regexp.Regexp
+	var multi []bool
+	var literals []string
 	n := 0
-	for i, match := range matches {
-		a, b := match[2], match[3]
-		literals[i] = s[n : a-1] // Need to leave off the colon
-		pats[i] = s[a:b]
+	for {
+		match := patternRe.FindStringSubmatchIndex(s[n:])
+		if match == nil {
+			break
+		}
+		a, b := n+match[2], n+match[3]
+		isMulti := match[2] == -1
+		if isMulti {
+			a, b = n+match[4], n+match[5]
+		}
+		// Need to leave off the colon (or asterisk)
+		literals = append(literals, s[n:a-1])
+		pats = append(pats, s[a:b])
+		multi = append(multi, isMulti)
+
+		var constraint *regexp.Regexp
+		if b < len(s) && s[b] == '{' {
+			e := closingBrace(s, b)
+			if e == -1 {
+				log.Fatalf("web: unterminated constraint in "+
+					"pattern %q", raw)
+			}
+			constraint = parseConstraint(raw, s[b+1:e])
+			b = e + 1
+		}
+		constraints = append(constraints, constraint)
+
 		if b == len(s) {
-			breaks[i] = '/'
+			breaks = append(breaks, '/')
 		} else {
-			breaks[i] = s[b]
+			breaks = append(breaks, s[b])
 		}
 		n = b
 	}
-	literals[len(matches)] = s[n:]
+	literals = append(literals, s[n:])
 	return stringPattern{
-		raw:      raw,
-		pats:     pats,
-		breaks:   breaks,
-		literals: literals,
-		wildcard: wildcard,
+		raw:         raw,
+		pats:        pats,
+		breaks:      breaks,
+		constraints: constraints,
+		multi:       multi,
+		literals:    literals,
+		wildcard:    wildcard,
 	}
 }
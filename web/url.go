@@ -0,0 +1,38 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// urlBuilder is implemented by Patterns which are able to generate paths that
+// they would match given a set of URL parameters.
+type urlBuilder interface {
+	buildURL(params map[string]string) (string, error)
+}
+
+func (rt *router) url(name string, params map[string]string) (string, error) {
+	rt.lock.Lock()
+	p, ok := rt.names[name]
+	rt.lock.Unlock()
+	if !ok {
+		return "", fmt.Errorf("web: no route named %q", name)
+	}
+
+	ub, ok := p.(urlBuilder)
+	if !ok {
+		return "", fmt.Errorf("web: route %q: cannot build URLs "+
+			"for %v", name, p)
+	}
+	path, err := ub.buildURL(params)
+	if err != nil {
+		return "", fmt.Errorf("web: route %q: %v", name, err)
+	}
+	return path, nil
+}
+
+// escapePath percent-encodes a generated path.
+func escapePath(path string) string {
+	u := url.URL{Path: path}
+	return u.String()
+}
@@ -0,0 +1,108 @@
+package web
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+var urlTests = []struct {
+	pat    PatternType
+	params map[string]string
+	url    string
+	err    bool
+}{
+	{"/hello", nil, "/hello", false},
+	{"/hello/:name", map[string]string{"name": "carl"}, "/hello/carl", false},
+	{"/hello/:name", map[string]string{}, "", true},
+	{"/hello/:name", map[string]string{"name": "a/b"}, "", true},
+	{"/hello/:name", map[string]string{"name": "c a"}, "/hello/c%20a", false},
+	{"/a/:b.:c", map[string]string{"b": "cat", "c": "tar.gz"},
+		"/a/cat.tar.gz", false},
+	{"/a/:b.:c", map[string]string{"b": "c.at", "c": "gz"}, "", true},
+	{"/users/:id{int}", map[string]string{"id": "42"}, "/users/42", false},
+	{"/users/:id{int}", map[string]string{"id": "carl"}, "", true},
+	{"/user/:user/*", map[string]string{"user": "bob"}, "/user/bob/", false},
+	{"/user/:user/*", map[string]string{"user": "bob", "*": "/friends/1"},
+		"/user/bob/friends/1", false},
+	{"/user/:user/*", map[string]string{"user": "bob", "*": "friends"},
+		"", true},
+	{"/blob/*path", map[string]string{"path": "a/b.go"}, "/blob/a/b.go",
+		false},
+	{"/blob/*path", nil, "", true},
+	{"/archive/:year(/:month)?", map[string]string{"year": "2015"},
+		"/archive/2015", false},
+	{"/archive/:year(/:month)?",
+		map[string]string{"year": "2015", "month": "04"},
+		"/archive/2015/04", false},
+	{"/archive/:year(/:month)?", map[string]string{"month": "04"}, "",
+		true},
+	{regexp.MustCompile(`^/greets/(?P<id>\d+)$`),
+		map[string]string{"id": "12"}, "/greets/12", false},
+	{regexp.MustCompile(`^/greets/(?P<id>\d+)$`),
+		map[string]string{"id": "carl"}, "", true},
+	{regexp.MustCompile(`^/greets/(?P<id>\d+)$`), nil, "", true},
+	{regexp.MustCompile(`^/a(\d+)/b(?P<b>\d+)`),
+		map[string]string{"$1": "1", "b": "2"}, "/a1/b2", false},
+	{regexp.MustCompile(`^/greets/\d+$`), nil, "", true},
+	{regexp.MustCompile(`^/(a|b)$`), map[string]string{"$1": "c"}, "", true},
+	{testPattern{}, nil, "", true},
+}
+
+func TestURL(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range urlTests {
+		m := New()
+		m.Get(test.pat, http.NotFound).Name("route")
+		url, err := m.URL("route", test.params)
+		if test.err {
+			if err == nil {
+				t.Errorf("Expected an error for %v with %v, got %q",
+					test.pat, test.params, url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %v with %v: %v",
+				test.pat, test.params, err)
+		} else if url != test.url {
+			t.Errorf("Expected %q for %v with %v, got %q", test.url,
+				test.pat, test.params, url)
+		}
+	}
+}
+
+func TestURLUnknownName(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	m.Get("/hello", http.NotFound).Name("hello")
+	if _, err := m.URL("goodbye", nil); err == nil {
+		t.Error("Expected an error for an unknown route name")
+	}
+	if url, err := m.URL("hello", nil); err != nil || url != "/hello" {
+		t.Errorf("Expected /hello, got %q (%v)", url, err)
+	}
+
+	// Renaming a route should drop the old name.
+	m.Post("/goodbye", http.NotFound).Name("bye").Name("goodbye")
+	if _, err := m.URL("bye", nil); err == nil {
+		t.Error("Expected an error for a route's old name")
+	}
+	if url, err := m.URL("goodbye", nil); err != nil || url != "/goodbye" {
+		t.Errorf("Expected /goodbye, got %q (%v)", url, err)
+	}
+}
+
+func TestURLRenameSame(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	r := m.Get("/hello", http.NotFound).Name("hello")
+	// Giving a route the name it already has is not a duplicate.
+	r.Name("hello")
+	if url, err := m.URL("hello", nil); err != nil || url != "/hello" {
+		t.Errorf("Expected /hello, got %q (%v)", url, err)
+	}
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"sort"
+	"strings"
+)
+
+// RouteInfo describes a route that has been added to a Mux.
+type RouteInfo struct {
+	// Methods is the sorted list of HTTP methods the route responds to,
+	// or nil if the route responds to every method (i.e., if it was added
+	// with Handle).
+	Methods []string
+	// Pattern is the PatternType the route was added with (see
+	// Match.RawPattern).
+	Pattern PatternType
+	// Handler is the HandlerType the route was added with (see
+	// Match.RawHandler).
+	Handler HandlerType
+	// Name is the route's name, or the empty string if it has not been
+	// named (see Route.Name).
+	Name string
+	// Meta is the route's metadata (see Route.Meta). It must not be
+	// modified.
+	Meta map[interface{}]interface{}
+}
+
+func (r route) info(names map[string]method) RouteInfo {
+	match := Match{Pattern: r.pattern, Handler: r.handler}
+	ri := RouteInfo{
+		Pattern: match.RawPattern(),
+		Handler: match.RawHandler(),
+		Name:    r.name,
+		Meta:    r.meta,
+	}
+	if r.method&mIDK == 0 {
+		ri.Methods = methodNames(r.method, names)
+	}
+	return ri
+}
+
+// Routes returns a description of every route that has been added to the Mux,
+// in the order in which they were added. Since routes are matched in the order
+// they were added, this is also the order in which Goji will consider them for
+// any given request.
+func (m *Mux) Routes() []RouteInfo {
+	m.rt.lock.Lock()
+	routes := make([]route, len(m.rt.routes))
+	copy(routes, m.rt.routes)
+	names := m.rt.methods
+	m.rt.lock.Unlock()
+
+	sort.Sort(routesByID(routes))
+	infos := make([]RouteInfo, len(routes))
+	for i, r := range routes {
+		infos[i] = r.info(names)
+	}
+	return infos
+}
+
+type routesByID []route
+
+func (r routesByID) Len() int           { return len(r) }
+func (r routesByID) Less(i, j int) bool { return r[i].id < r[j].id }
+func (r routesByID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// WalkFunc is the type of the function called by Walk for each route it
+// visits. The prefix is the path prefix under which the Mux containing the
+// route was mounted, and is the empty string for routes in the outermost Mux.
+// If WalkFunc returns an error, Walk stops and returns that error.
+type WalkFunc func(prefix string, route RouteInfo) error
+
+/*
+Walk calls fn for each route in the given Mux, in the order they were added (see
+Mux.Routes). If a route's handler is itself a Mux, and the route's pattern is a
+string pattern ending in "/*" (as in Handle("/admin/*", admin)), Walk will visit
+the route and then descend into the nested Mux, calling fn for each of its
+routes with the mounted pattern (less the trailing "/*") as the prefix.
+
+Note that Goji does not itself strip prefixes from requests. If the nested Mux
+uses a middleware like middleware.SubRouter, its routes are matched against the
+path following the prefix, and the full path of a route is the prefix followed
+by its pattern. Otherwise, its routes are matched against the full path.
+*/
+func Walk(m *Mux, fn WalkFunc) error {
+	return walk("", m, fn)
+}
+
+func walk(prefix string, m *Mux, fn WalkFunc) error {
+	for _, ri := range m.Routes() {
+		if err := fn(prefix, ri); err != nil {
+			return err
+		}
+		sub, ok := ri.Handler.(*Mux)
+		if !ok {
+			continue
+		}
+		if p, ok := ri.Pattern.(string); ok && strings.HasSuffix(p, "/*") {
+			err := walk(prefix+strings.TrimSuffix(p, "/*"), sub, fn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
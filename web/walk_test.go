@@ -0,0 +1,118 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	t.Parallel()
+	m := New()
+	re := regexp.MustCompile(`^/greets/(?P<id>\d+)$`)
+
+	m.Get("/b", http.NotFound).Name("b")
+	m.Post("/a", http.NotFound)
+	m.Handle("/", http.NotFound)
+	m.Delete(re, http.NotFound)
+	m.Method("PROPFIND", "/dav", http.NotFound)
+
+	routes := m.Routes()
+	expected := []struct {
+		methods []string
+		pattern PatternType
+		name    string
+	}{
+		{[]string{"GET", "HEAD"}, "/b", "b"},
+		{[]string{"POST"}, "/a", ""},
+		{nil, "/", ""},
+		{[]string{"DELETE"}, re, ""},
+		{[]string{"PROPFIND"}, "/dav", ""},
+	}
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected %d routes, got %d", len(expected), len(routes))
+	}
+	for i, e := range expected {
+		r := routes[i]
+		if !reflect.DeepEqual(r.Methods, e.methods) || r.Pattern != e.pattern ||
+			r.Name != e.name {
+			t.Errorf("Route %d: expected %v %v %q, got %v %v %q", i,
+				e.methods, e.pattern, e.name, r.Methods, r.Pattern,
+				r.Name)
+		}
+		if !funcEqual(r.Handler, http.NotFound) {
+			t.Errorf("Route %d: unexpected handler %#v", i, r.Handler)
+		}
+	}
+}
+
+func TestRoutesGroup(t *testing.T) {
+	t.Parallel()
+	m := New()
+	g := m.Group("/greet")
+	g.Get(regexp.MustCompile(`^/(?P<id>\d+)$`), http.NotFound)
+	host := Host("a.com", "/x")
+	g.Get(host, http.NotFound)
+
+	routes := m.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(routes))
+	}
+	re, ok := routes[0].Pattern.(*regexp.Regexp)
+	if !ok || re.String() != `^/greet/(?P<id>\d+)$` {
+		t.Errorf("Expected a prefixed regexp, got %#v", routes[0].Pattern)
+	}
+	if !reflect.DeepEqual(routes[1].Pattern, host) {
+		t.Errorf("Expected %v, got %#v", host, routes[1].Pattern)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+	m := New()
+	admin := New()
+	api := New()
+
+	m.Get("/", http.NotFound)
+	m.Handle("/admin/*", admin)
+	admin.Get("/", http.NotFound)
+	admin.Handle("/api/*", api)
+	api.Post("/widgets", http.NotFound)
+	m.Get("/about", http.NotFound)
+
+	var visited []string
+	err := Walk(m, func(prefix string, ri RouteInfo) error {
+		visited = append(visited, prefix+" "+ri.Pattern.(string))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		" /",
+		" /admin/*",
+		"/admin /",
+		"/admin /api/*",
+		"/admin/api /widgets",
+		" /about",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Expected %v, got %v", expected, visited)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = Walk(m, func(prefix string, ri RouteInfo) error {
+		n++
+		if n == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 3 {
+		t.Errorf("Expected Walk to stop after 3 routes, got %d (%v)",
+			n, err)
+	}
+}
@@ -66,6 +66,15 @@ accepted:
 		- a path segment starting with a colon will match any
 		  string placed at that position. e.g., "/:name" will match
 		  "/carl", binding "name" to "carl".
+		- a named segment may be followed by a constraint in braces,
+		  in which case it will only match strings satisfying that
+		  constraint. The constraint is either a regular expression
+		  (which must match the entire segment), or one of the names
+		  "int", "uint", "alpha", "alnum", "hex", or "uuid". e.g.,
+		  "/users/:id{int}" will match "/users/123" but not
+		  "/users/carl", and "/files/:name{[a-z0-9-]+}.txt" will match
+		  "/files/my-notes.txt". Requests that do not satisfy the
+		  constraint fall through to subsequent routes.
 		- a pattern ending with "/*" will match any route with that
 		  prefix. For instance, the pattern "/u/:name/*" will match
 		  "/u/carl/" and "/u/carl/projects/123", but not "/u/carl"
@@ -74,10 +83,23 @@ accepted:
 		  unmatched tail of the match, but including the leading "/". So
 		  for the two matching examples above, "*" would be bound to "/"
 		  and "/projects/123" respectively.
+		- a path segment starting with an asterisk is a named
+		  catch-all, which matches any non-empty string (including
+		  slashes), and may appear anywhere in the pattern. e.g.,
+		  "/repos/:repo/blob/*path" will match
+		  "/repos/goji/blob/web/web.go", binding "path" to
+		  "web/web.go". Catch-alls match as much of the path as they
+		  can while allowing the rest of the pattern to match.
+		- a portion of the pattern in parentheses followed by a
+		  question mark is optional. Optional groups may be nested.
+		  e.g., "/archive/:year(/:month)?" will match both
+		  "/archive/2015" and "/archive/2015/04". Parameters in
+		  absent groups are not bound.
 	  Unlike http.ServeMux's patterns, string patterns support neither the
 	  "rooted subtree" behavior nor Host-specific routes. Users who require
-	  either of these features are encouraged to compose package http's mux
-	  with the mux provided by this package.
+	  the former are encouraged to compose package http's mux with the mux
+	  provided by this package, and users who require the latter should
+	  see the Host function.
 	- regexp.Regexp, which is assumed to be a Perl-style regular expression
 	  that is anchored on the left (i.e., the beginning of the string). If
 	  your regular expression is not anchored on the left, a